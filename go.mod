@@ -24,6 +24,7 @@ require (
 	go.octolab.org/toolkit/cli v0.2.0
 	go.octolab.org/toolkit/config v0.0.4
 	golang.org/x/sync v0.0.0-20201020160332-67f06af15bc9
+	gopkg.in/yaml.v2 v2.3.0
 )
 
 // hg -> git
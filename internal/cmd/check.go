@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/progress"
+)
+
+// NewCheckCommand returns command to check metrics coverage by thresholds.
+func NewCheckCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		options  coverageOptions
+		minimum  float64
+		baseline string
+		policy   string
+		save     bool
+	)
+
+	command := cobra.Command{
+		Use:   "check",
+		Short: "checks metrics coverage by thresholds",
+		Long: "Checks metrics coverage by thresholds. " +
+			"It fails if the coverage is below the minimum or dropped compared with the baseline.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if save && baseline == "" {
+				return errors.New("please provide a baseline file to update")
+			}
+			return validateCoverage(config, options)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+			printer.SetPrefix(config.Graphite.Prefix)
+
+			metrics, dashboard, err := fetchCoverageData(cmd.Context(), config, options, logger, progress.New())
+			if err != nil {
+				return err
+			}
+
+			reporter, err := newCoverageReporter(dashboard, options.source)
+			if err != nil {
+				return err
+			}
+
+			fs := afero.NewOsFs()
+			metrics = metrics.Filter(config.FilterQuery().MustCompile()).Sort()
+			report := reporter.CoverageReport(metrics)
+
+			var previous *model.CoverageReport
+			if baseline != "" {
+				previous, err = loadReport(fs, baseline)
+				if err != nil && !(save && os.IsNotExist(errors.Cause(err))) {
+					return err
+				}
+			}
+
+			check := report.Check(minimum, previous)
+			if err := printer.PrintCoverageCheck(check); err != nil {
+				return err
+			}
+			if policy != "" {
+				result, err := applyPolicy(fs, policy, report, printer)
+				if err != nil {
+					return err
+				}
+				if !result.Passed() {
+					check.Failures = append(check.Failures,
+						fmt.Sprintf("policy has %d violation(s)", result.Violations()))
+				}
+			}
+			if !check.Passed() {
+				return errors.New(strings.Join(check.Failures, "; "))
+			}
+			if save {
+				return storeReport(fs, baseline, report)
+			}
+			return nil
+		},
+	}
+
+	options.bind(&command)
+	options.bindSource(&command)
+	flags := command.Flags()
+	flags.Float64Var(&minimum, "min-coverage", 0, "the minimum coverage in percents")
+	flags.StringVar(&baseline, "baseline", "", "a file with the coverage report to compare with, e.g. produced by coverage -f json")
+	flags.StringVar(&policy, "policy", "", "a TOML or JSON file with the coverage policy rules")
+	flags.BoolVar(&save, "update-baseline", false, "store the coverage report to the baseline file if the check passed")
+
+	return &command
+}
@@ -0,0 +1,99 @@
+package cmd_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kamilsk/grafaman/internal/cmd"
+)
+
+var _ = Describe("check metrics coverage", func() {
+	BeforeEach(func() {
+		buffer.Reset()
+
+		root = New()
+		root.SetErr(buffer)
+		root.SetOut(buffer)
+	})
+
+	When("invalid usage", func() {
+		It("returns an error if a baseline to update is omitted", func() {
+			root.SetArgs([]string{
+				"check",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--update-baseline",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide a baseline file to update"))
+		})
+
+		It("returns an error if a Graphite API endpoint is omitted", func() {
+			root.SetArgs([]string{
+				"check",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Graphite API endpoint"))
+		})
+	})
+
+	When("correct usage", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics/find", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`[
+					{"id":"apps.services.awesome-service.api.rps","leaf":1},
+					{"id":"apps.services.awesome-service.api.errors","leaf":1}
+				]`))
+			})
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("compares with a baseline written by the coverage command", func() {
+			dir, err := ioutil.TempDir("", "grafaman")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { _ = os.RemoveAll(dir) }()
+			baseline := filepath.Join(dir, "baseline.json")
+
+			args := []string{
+				"--dashboard-manifest", "testdata/manifests.yaml",
+				"--dashboard-merge",
+				"--graphite", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--no-cache",
+			}
+			root.SetArgs(append([]string{"coverage", "-f", "json"}, args...))
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(ioutil.WriteFile(baseline, buffer.Bytes(), 0644)).To(Succeed())
+			written := buffer.String()
+
+			buffer.Reset()
+			root = New()
+			root.SetErr(buffer)
+			root.SetOut(buffer)
+			root.SetArgs(append([]string{"check", "--baseline", baseline, "--update-baseline"}, args...))
+			Expect(root.Execute()).ToNot(HaveOccurred())
+
+			stored, err := ioutil.ReadFile(baseline)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(stored)).To(Equal(written))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/history"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/progress"
+)
+
+// NewChurnCommand returns command to report metrics added and removed between two runs.
+func NewChurnCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		depth    int
+		from, to int
+		noCache  bool
+	)
+
+	command := cobra.Command{
+		Use:   "churn",
+		Short: "reports metrics added and removed between two runs",
+		Long: "Reports metrics added and removed between two runs stored in the coverage history. " +
+			"If a dashboard is provided, then removed metrics still referenced by its queries are reported too.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if config.Graphite.Prefix == "" {
+				return errors.New("please provide metric prefix")
+			}
+			if prefix := config.Graphite.Prefix; !model.Metric(prefix).Valid() {
+				return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
+			}
+			if config.Grafana.Dashboard != "" && config.Grafana.URL == "" {
+				return errors.New("please provide Grafana API endpoint")
+			}
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+			printer.SetPrefix(config.Graphite.Prefix)
+
+			records, err := history.New(afero.NewOsFs(), logger).Load(config.Graphite.Prefix)
+			if err != nil {
+				return err
+			}
+			from, to, err := runs(len(records), from, to)
+			if err != nil {
+				return err
+			}
+
+			if !records[from].SameFilters(records[to]) {
+				cmd.PrintErrf("runs %d and %d are taken with different --exclude or --filter, "+
+					"metrics changed by them are reported too\n", from+1, to+1)
+			}
+			churn := model.NewMetricChurn(records[from].Metrics(), records[to].Metrics())
+			if config.Grafana.Dashboard != "" {
+				indicator := progress.New()
+				if err := resolveOrg(cmd.Context(), config, logger, indicator); err != nil {
+					return err
+				}
+				dashboard, err := fetchDashboard(cmd.Context(), config, linkOptions{}, noCache, logger, indicator)
+				if err != nil {
+					return err
+				}
+				queries, err := dashboard.Queries(model.Config{Unpack: true})
+				if err != nil {
+					return err
+				}
+				churn.Reference(queries)
+			}
+
+			return printer.PrintMetricChurn(churn, depth)
+		},
+	}
+
+	flags := command.Flags()
+	flags.IntVar(&depth, "depth", 1, "the depth of subtrees to group metrics")
+	flags.IntVar(&from, "from", 0, "the number of the run to compare from; the previous run by default")
+	flags.IntVar(&to, "to", 0, "the number of the run to compare to; the last run by default")
+	flags.BoolVar(&noCache, "no-cache", false, "disable caching")
+
+	return &command
+}
@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"go.octolab.org/safe"
+	xtime "go.octolab.org/time"
+	"go.octolab.org/unsafe"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/provider/file"
+	"github.com/kamilsk/grafaman/internal/provider/grafana"
+	dashboards "github.com/kamilsk/grafaman/internal/provider/grafana/cache"
+	"github.com/kamilsk/grafaman/internal/provider/graphite"
+	"github.com/kamilsk/grafaman/internal/provider/graphite/cache"
+	"github.com/kamilsk/grafaman/internal/provider/jsonnet"
+	"github.com/kamilsk/grafaman/internal/repl"
+)
+
+// coverageOptions contains options of commands based on the coverage pipeline.
+type coverageOptions struct {
+	exclude []string
+	last    time.Duration
+	noCache bool
+	source  string
+	version int
+	history bool // only unified alerts are needed, versions of the dashboard are fetched separately
+	links   linkOptions
+	files   []string
+	k8s     struct {
+		manifests []string
+		label     string
+		merge     bool
+	}
+	jsonnet struct {
+		file    string
+		binary  string
+		options jsonnet.Options
+	}
+}
+
+func (options *coverageOptions) bind(command *cobra.Command) {
+	flags := command.Flags()
+	flags.StringArrayVar(&options.exclude, "exclude", nil, "queries to exclude metrics from coverage, e.g. *.median")
+	flags.DurationVar(&options.last, "last", xtime.Day, "the last interval to fetch")
+	flags.BoolVar(&options.noCache, "no-cache", false, "disable caching")
+	options.links.bind(command)
+	flags.StringArrayVar(&options.files, "dashboard-file", nil,
+		"an exported dashboard JSON file, a glob or a directory to read instead of fetching it from Grafana")
+	flags.StringArrayVar(&options.k8s.manifests, "dashboard-manifest", nil,
+		"a Kubernetes manifest, a glob or a directory to read dashboards from ConfigMaps instead of fetching them from Grafana")
+	flags.StringVar(&options.k8s.label, "dashboard-label", "grafana_dashboard",
+		"the label of ConfigMaps with dashboards, like the Grafana sidecar uses")
+	flags.BoolVar(&options.k8s.merge, "dashboard-merge", false,
+		"report the joint coverage by dashboards of manifests instead of coverage by each of them")
+	flags.StringVar(&options.jsonnet.file, "dashboard-jsonnet", "",
+		"a Jsonnet file to evaluate a dashboard instead of fetching it from Grafana")
+	flags.StringVar(&options.jsonnet.binary, "jsonnet-binary", "jsonnet", "the jsonnet executable to evaluate the dashboard")
+	flags.StringArrayVarP(&options.jsonnet.options.LibPaths, "jpath", "J", nil, "a library search directory for Jsonnet")
+	flags.StringToStringVar(&options.jsonnet.options.ExtStr, "ext-str", nil, "an external string variable for Jsonnet")
+	flags.StringToStringVar(&options.jsonnet.options.ExtCode, "ext-code", nil, "an external code variable for Jsonnet")
+	flags.StringToStringVar(&options.jsonnet.options.TLAStr, "tla-str", nil, "a top-level string argument for Jsonnet")
+	flags.StringToStringVar(&options.jsonnet.options.TLACode, "tla-code", nil, "a top-level code argument for Jsonnet")
+}
+
+// linkOptions contains options to follow links to other dashboards.
+type linkOptions struct {
+	follow bool
+	depth  int
+}
+
+func (options *linkOptions) bind(command *cobra.Command) {
+	flags := command.Flags()
+	flags.BoolVar(&options.follow, "follow-links", false, "merge queries of dashboards linked by the dashboard and its panels")
+	flags.IntVar(&options.depth, "links-depth", 2, "the maximum depth of links to follow")
+}
+
+// fetch returns the dashboard merged with linked ones if it is enabled.
+// Linked dashboards that failed to fetch, e.g. deleted ones, are skipped.
+func (options linkOptions) fetch(
+	ctx context.Context,
+	provider dashboards.Grafana,
+	uid string,
+	logger *logrus.Logger,
+) (*model.Dashboard, error) {
+	dashboard, err := provider.Fetch(ctx, uid)
+	if err != nil || !options.follow {
+		return dashboard, err
+	}
+	return model.FollowLinks(dashboard, options.depth, func(uid string) (*model.Dashboard, error) {
+		linked, err := provider.Fetch(ctx, uid)
+		if err != nil && ctx.Err() == nil {
+			logger.WithError(err).WithField("dashboard", uid).Warning("skip linked dashboard")
+			return nil, nil
+		}
+		return linked, err
+	})
+}
+
+// bindSource binds the flag to choose queries of which dashboard parts cover metrics.
+func (options *coverageOptions) bindSource(command *cobra.Command) {
+	command.Flags().StringVar(&options.source, "coverage-by", string(model.SourcePanels),
+		"queries of which dashboard parts cover metrics: panels, alerts or both")
+}
+
+// collapseOptions contains options to collapse high-cardinality segments of metrics.
+type collapseOptions struct {
+	enabled bool
+	fanOut  int
+	rules   map[string]string
+}
+
+func (options *collapseOptions) bind(command *cobra.Command) {
+	flags := command.Flags()
+	flags.BoolVar(&options.enabled, "collapse", false, "collapse metrics with dynamic segments into families")
+	flags.IntVar(&options.fanOut, "collapse-fan-out", model.DefaultFanOut,
+		"the number of children after which they are treated as dynamic, 0 disables the check")
+	flags.StringToStringVar(&options.rules, "collapse-rule", nil,
+		"a placeholder and a regexp of dynamic segments, e.g. pod='^pod-[a-z0-9]+$', it overrides the default one")
+}
+
+// cardinality returns the default rules extended by the provided ones.
+func (options *collapseOptions) cardinality() (model.Cardinality, error) {
+	cardinality := model.DefaultCardinality()
+	cardinality.FanOut = options.fanOut
+
+	names := make([]string, 0, len(options.rules))
+	for name := range options.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern, err := regexp.Compile(options.rules[name])
+		if err != nil {
+			return cardinality, errors.Wrapf(err, "invalid collapse rule %q", name)
+		}
+		rule := model.SegmentRule{Name: name, Pattern: pattern}
+
+		var present bool
+		for i := range cardinality.Rules {
+			if cardinality.Rules[i].Name == name {
+				cardinality.Rules[i], present = rule, true
+				break
+			}
+		}
+		if !present {
+			cardinality.Rules = append(cardinality.Rules, rule)
+		}
+	}
+	return cardinality, nil
+}
+
+// validateCoverage checks the configuration required by the coverage pipeline.
+// Grafana is not required if the dashboard is evaluated from Jsonnet or read from files.
+func validateCoverage(config *cnf.Config, options coverageOptions) error {
+	var sources int
+	for _, enabled := range []bool{len(options.files) > 0, len(options.k8s.manifests) > 0, options.jsonnet.file != ""} {
+		if enabled {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("please use only one of --dashboard-file, --dashboard-manifest and --dashboard-jsonnet")
+	}
+	if sources == 0 {
+		if config.Grafana.URL == "" {
+			return errors.New("please provide Grafana API endpoint")
+		}
+		if config.Grafana.Dashboard == "" && config.Grafana.Org != allOrgs {
+			return errors.New("please provide a dashboard unique identifier")
+		}
+	}
+	if options.source != "" && !model.CoverageSource(options.source).Valid() {
+		return errors.Errorf("invalid coverage source: %s; it must be panels, alerts or both", options.source)
+	}
+	if config.Graphite.URL == "" {
+		return errors.New("please provide Graphite API endpoint")
+	}
+	if config.Graphite.Prefix == "" {
+		return errors.New("please provide metric prefix")
+	}
+	if prefix := config.Graphite.Prefix; !model.Metric(prefix).Valid() {
+		return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
+	}
+	return nil
+}
+
+// fetchCoverageData concurrently fetches metrics from Graphite
+// without excluded ones and a dashboard from Grafana.
+// Unified alerting rules are fetched too if alerts cover metrics.
+func fetchCoverageData(
+	ctx context.Context,
+	config *cnf.Config,
+	options coverageOptions,
+	logger *logrus.Logger,
+	indicator grafana.ProgressListener,
+) (model.Metrics, *model.Dashboard, error) {
+	if len(options.files) == 0 && len(options.k8s.manifests) == 0 && options.jsonnet.file == "" {
+		if err := resolveOrg(ctx, config, logger, indicator); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var (
+		metrics   model.Metrics
+		dashboard *model.Dashboard
+		alerts    []model.Alert
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var provider cache.Graphite
+		provider, err := graphite.New(config.Graphite.URL, &http.Client{Timeout: config.Graphite.Timeout}, logger, indicator)
+		if err != nil {
+			return err
+		}
+		if !options.noCache {
+			provider = cache.Decorate(provider, afero.NewOsFs(), logger)
+		}
+
+		metrics, err = provider.Fetch(ctx, config.Graphite.Prefix, options.last)
+		if err != nil {
+			return err
+		}
+
+		metrics = metrics.Exclude(new(model.Queries).Convert(options.exclude).MustMatchers()...)
+		return nil
+	})
+	g.Go(func() error {
+		if len(options.files) > 0 {
+			var err error
+			dashboard, err = file.New(afero.NewOsFs(), logger).Fetch(ctx, options.files)
+			return err
+		}
+		if len(options.k8s.manifests) > 0 {
+			provider := file.New(afero.NewOsFs(), logger)
+			dashboards, err := provider.Manifests(ctx, options.k8s.manifests, options.k8s.label)
+			if err != nil {
+				return err
+			}
+			dashboard = model.MergeDashboards(dashboards...)
+			return nil
+		}
+		if options.jsonnet.file != "" {
+			var err error
+			provider := jsonnet.New(jsonnet.Binary(options.jsonnet.binary), options.jsonnet.options, logger)
+			dashboard, err = provider.Fetch(ctx, options.jsonnet.file)
+			return err
+		}
+
+		if options.history {
+			dashboard = new(model.Dashboard)
+			return nil
+		}
+		if options.version > 0 {
+			provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+			if err != nil {
+				return err
+			}
+			dashboard, err = provider.FetchVersion(ctx, config.Grafana.Dashboard, options.version)
+			return err
+		}
+
+		var err error
+		dashboard, err = fetchDashboard(ctx, config, options.links, options.noCache, logger, indicator)
+		return err
+	})
+	if source := model.CoverageSource(options.source); source == model.SourceAlerts || source == model.SourceBoth {
+		g.Go(func() error {
+			if config.Grafana.URL == "" {
+				return nil
+			}
+			provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+			if err != nil {
+				return err
+			}
+			alerts, err = provider.Alerts(ctx)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	for _, part := range append([]*model.Dashboard{dashboard}, dashboard.Parts...) {
+		part.Alerts = append(part.Alerts, alerts...)
+		part.Pin(config.Grafana.Variables)
+	}
+	return metrics, dashboard, nil
+}
+
+// allOrgs is a special value of the Grafana organization to search dashboards in all of them.
+const allOrgs = "all"
+
+// grafanaClient returns the HTTP client for Grafana API requests.
+func grafanaClient(config *cnf.Config) grafana.Client {
+	org := config.Grafana.Org
+	if org == allOrgs {
+		org = ""
+	}
+	return grafana.WithOrg(&http.Client{Timeout: config.Grafana.Timeout}, org)
+}
+
+// resolveOrg replaces the name of the Grafana organization by its identifier.
+func resolveOrg(
+	ctx context.Context,
+	config *cnf.Config,
+	logger *logrus.Logger,
+	indicator grafana.ProgressListener,
+) error {
+	org := config.Grafana.Org
+	if org == "" || org == allOrgs {
+		return nil
+	}
+	if _, err := strconv.Atoi(org); err == nil {
+		return nil
+	}
+
+	// the organization is not resolved yet, so the request must not be scoped by it
+	client := &http.Client{Timeout: config.Grafana.Timeout}
+	provider, err := grafana.New(config.Grafana.URL, client, logger, indicator)
+	if err != nil {
+		return err
+	}
+	orgs, err := provider.Orgs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, candidate := range orgs {
+		if strings.EqualFold(candidate.Name, org) {
+			config.Grafana.Org = strconv.Itoa(candidate.ID)
+			return nil
+		}
+	}
+	return errors.Errorf("grafana organization %q is not found", org)
+}
+
+// fetchDashboard fetches the dashboard from Grafana
+// or searches it in all organizations if it is requested.
+func fetchDashboard(
+	ctx context.Context,
+	config *cnf.Config,
+	links linkOptions,
+	noCache bool,
+	logger *logrus.Logger,
+	indicator grafana.ProgressListener,
+) (*model.Dashboard, error) {
+	if config.Grafana.Org == allOrgs {
+		return fetchAllOrgs(ctx, config, links, noCache, logger, indicator)
+	}
+
+	var provider dashboards.Grafana
+	provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+	if err != nil {
+		return nil, err
+	}
+	if !noCache {
+		provider = dashboards.Decorate(provider, cacheScope(config, config.Grafana.Org), afero.NewOsFs(), logger)
+	}
+	return links.fetch(ctx, provider, config.Grafana.Dashboard, logger)
+}
+
+// cacheScope returns the scope of cached dashboards of the Grafana organization,
+// unique identifiers are unique only within an organization of a Grafana instance.
+func cacheScope(config *cnf.Config, org string) string {
+	host := config.Grafana.URL
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if org == "" {
+		return host
+	}
+	return host + "-org-" + org
+}
+
+// fetchAllOrgs searches the dashboard, or all of them if it is not specified,
+// in every organization the credentials can access and merges found ones.
+func fetchAllOrgs(
+	ctx context.Context,
+	config *cnf.Config,
+	links linkOptions,
+	noCache bool,
+	logger *logrus.Logger,
+	indicator grafana.ProgressListener,
+) (*model.Dashboard, error) {
+	provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := provider.Orgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*model.Dashboard
+	for _, org := range orgs {
+		id := strconv.Itoa(org.ID)
+		client := grafana.WithOrg(&http.Client{Timeout: config.Grafana.Timeout}, id)
+		provider, err := grafana.New(config.Grafana.URL, client, logger, indicator)
+		if err != nil {
+			return nil, err
+		}
+		refs, err := provider.Search(ctx, config.Grafana.Dashboard)
+		if err != nil {
+			return nil, err
+		}
+		var fetcher dashboards.Grafana = provider
+		if !noCache {
+			fetcher = dashboards.Decorate(provider, cacheScope(config, id), afero.NewOsFs(), logger)
+		}
+		for _, ref := range refs {
+			dashboard, err := links.fetch(ctx, fetcher, ref.UID, logger)
+			if err != nil {
+				return nil, err
+			}
+			logger.WithField("org", org.Name).WithField("dashboard", ref.UID).Debug("dashboard is found")
+			found = append(found, dashboard)
+		}
+	}
+	if len(found) == 0 {
+		if config.Grafana.Dashboard != "" {
+			return nil, errors.Errorf("dashboard %q is not found in any organization", config.Grafana.Dashboard)
+		}
+		return nil, errors.New("there are no dashboards in any organization")
+	}
+	return model.MergeDashboards(found...), nil
+}
+
+// newCoverageReporter returns a reporter by queries of the dashboard parts defined by the source.
+func newCoverageReporter(dashboard *model.Dashboard, source string) (repl.CoverageReporter, error) {
+	cfg := model.Config{NeedSorting: true, Unpack: true}
+	queries, err := dashboard.Queries(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if source == "" || model.CoverageSource(source) == model.SourcePanels {
+		return model.NewCoverageReporter(queries), nil
+	}
+	alerts, err := dashboard.AlertQueries(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewSourceCoverageReporter(queries, alerts, model.CoverageSource(source)), nil
+}
+
+// window is a duration that also accepts days, e.g. 30d.
+type window time.Duration
+
+func (w *window) Set(value string) error {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return errors.Errorf("invalid duration %q", value)
+		}
+		*w = window(time.Duration(n) * xtime.Day)
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.Errorf("invalid duration %q", value)
+	}
+	*w = window(duration)
+	return nil
+}
+
+func (w *window) String() string {
+	return time.Duration(*w).String()
+}
+
+func (w *window) Type() string {
+	return "duration"
+}
+
+// loadReport reads a coverage report in JSON format, e.g. produced by coverage -f json.
+func loadReport(fs afero.Fs, filename string) (*model.CoverageReport, error) {
+	file, err := fs.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "open coverage report")
+	}
+	defer safe.Close(file, unsafe.Ignore)
+
+	report := new(model.CoverageReport)
+	if err := json.NewDecoder(file).Decode(report); err != nil {
+		return nil, errors.Wrapf(err, "decode coverage report %q", filename)
+	}
+	return report, nil
+}
+
+// storeReport writes a coverage report in JSON format, the same as coverage -f json.
+func storeReport(fs afero.Fs, filename string, report model.CoverageReport) error {
+	file, err := fs.Create(filename)
+	if err != nil {
+		return errors.Wrap(err, "create coverage report")
+	}
+	defer safe.Close(file, unsafe.Ignore)
+
+	printer := new(presenter.Printer)
+	if err := printer.SetOutput(file).SetFormat("json"); err != nil {
+		return err
+	}
+	return errors.Wrapf(printer.PrintCoverageReport(report), "encode coverage report %q", filename)
+}
+
+// applyPolicy evaluates the coverage report by the policy from the file and prints the result.
+func applyPolicy(
+	fs afero.Fs,
+	filename string,
+	report model.CoverageReport,
+	printer *presenter.Printer,
+) (model.PolicyReport, error) {
+	policy, err := cnf.LoadPolicy(fs, filename)
+	if err != nil {
+		return model.PolicyReport{}, err
+	}
+	result := policy.Evaluate(report)
+	return result, printer.PrintPolicyReport(result)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/progress"
+	"github.com/kamilsk/grafaman/internal/provider/grafana"
+	"github.com/kamilsk/grafaman/internal/provider/grafana/cache"
+)
+
+// NewConsumersCommand returns command to find dashboards that use metrics.
+func NewConsumersCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		concurrency int
+		noCache     bool
+	)
+
+	command := cobra.Command{
+		Use:   "consumers",
+		Short: "finds dashboards that use metrics",
+		Long: "Finds dashboards that use metrics. " +
+			"It walks all dashboards found by Grafana search and lists panels and queries that reference the prefix.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if config.Grafana.URL == "" {
+				return errors.New("please provide Grafana API endpoint")
+			}
+			if config.Graphite.Prefix == "" {
+				return errors.New("please provide metric prefix")
+			}
+			if prefix := config.Graphite.Prefix; !model.Metric(prefix).Valid() {
+				return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
+			}
+			if concurrency < 1 {
+				return errors.New("please provide a positive concurrency")
+			}
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+
+			ctx, indicator := cmd.Context(), progress.New()
+			if err := resolveOrg(ctx, config, logger, indicator); err != nil {
+				return err
+			}
+
+			var orgs []model.Org
+			if config.Grafana.Org == allOrgs {
+				provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+				if err != nil {
+					return err
+				}
+				if orgs, err = provider.Orgs(ctx); err != nil {
+					return err
+				}
+			} else {
+				orgs = []model.Org{{}}
+			}
+
+			var (
+				consumers      model.Consumers
+				total, skipped int
+			)
+			for _, org := range orgs {
+				client, scope := grafanaClient(config), cacheScope(config, config.Grafana.Org)
+				if org.ID != 0 {
+					id := strconv.Itoa(org.ID)
+					client, scope = grafana.WithOrg(&http.Client{Timeout: config.Grafana.Timeout}, id), cacheScope(config, id)
+				}
+				provider, err := grafana.New(config.Grafana.URL, client, logger, indicator)
+				if err != nil {
+					return err
+				}
+				refs, err := provider.Search(ctx, "")
+				if err != nil {
+					return err
+				}
+				for i := range refs {
+					refs[i].Org = org.Name
+				}
+
+				var fetcher cache.Grafana = provider
+				if !noCache {
+					fetcher = cache.Decorate(provider, scope, afero.NewOsFs(), logger)
+				}
+				found, failed, err := findConsumers(ctx, fetcher, refs, config.Graphite.Prefix, concurrency, logger)
+				if err != nil {
+					return err
+				}
+				consumers = append(consumers, found...)
+				total, skipped = total+len(refs), skipped+failed
+			}
+
+			if skipped > 0 {
+				cmd.PrintErrf("%d of %d dashboards are skipped because they failed to fetch or process\n", skipped, total)
+			}
+			return printer.PrintConsumers(consumers.Sort())
+		},
+	}
+
+	flags := command.Flags()
+	flags.IntVar(&concurrency, "concurrency", 4, "the maximum number of dashboards fetched at the same time")
+	flags.BoolVar(&noCache, "no-cache", false, "disable caching")
+
+	return &command
+}
+
+// findConsumers concurrently fetches the dashboards and collects their queries that reference the prefix.
+// Dashboards that failed to fetch or have invalid queries are skipped and counted.
+func findConsumers(
+	ctx context.Context,
+	provider cache.Grafana,
+	refs []model.DashboardRef,
+	prefix string,
+	concurrency int,
+	logger *logrus.Logger,
+) (model.Consumers, int, error) {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		consumers model.Consumers
+		skipped   int
+	)
+
+	skip := func(err error, ref model.DashboardRef) {
+		logger.WithError(err).WithField("dashboard", ref.UID).Warning("skip dashboard")
+		mu.Lock()
+		skipped++
+		mu.Unlock()
+	}
+
+	limit := make(chan struct{}, concurrency)
+	for _, ref := range refs {
+		ref := ref
+		select {
+		case limit <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, 0, ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-limit; wg.Done() }()
+
+			dashboard, err := provider.Fetch(ctx, ref.UID)
+			if err != nil {
+				skip(err, ref)
+				return
+			}
+			dashboard.Prefix = prefix
+			targets, err := dashboard.Targets(model.Config{NeedSorting: true, Unpack: true})
+			if err != nil {
+				skip(err, ref)
+				return
+			}
+
+			mu.Lock()
+			consumers = append(consumers, model.NewConsumers(ref, targets)...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	return consumers, skipped, nil
+}
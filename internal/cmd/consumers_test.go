@@ -0,0 +1,102 @@
+package cmd_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kamilsk/grafaman/internal/cmd"
+)
+
+var _ = Describe("find consumers of metrics", func() {
+	BeforeEach(func() {
+		buffer.Reset()
+
+		root = New()
+		root.SetErr(buffer)
+		root.SetOut(buffer)
+	})
+
+	When("invalid usage", func() {
+		It("returns an error if a Grafana API endpoint is omitted", func() {
+			root.SetArgs([]string{"consumers", "-m", "apps.services.awesome-service"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Grafana API endpoint"))
+		})
+
+		It("returns an error if a subset of metrics is omitted", func() {
+			root.SetArgs([]string{"consumers", "--grafana", grafana.URL})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide metric prefix"))
+		})
+
+		It("returns an error if a concurrency is not positive", func() {
+			root.SetArgs([]string{
+				"consumers",
+				"--grafana", grafana.URL,
+				"-m", "apps.services.awesome-service",
+				"--concurrency", "0",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide a positive concurrency"))
+		})
+	})
+
+	When("correct usage", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/search", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get("type")).To(Equal("dash-db"))
+				_, _ = rw.Write([]byte(`[
+					{"uid":"service","title":"Awesome Service","folderTitle":"Services"},
+					{"uid":"other","title":"Other Service"},
+					{"uid":"deleted","title":"Deleted Service"}
+				]`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/service", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`{"dashboard":{"uid":"service","panels":[
+					{"id":1,"type":"graph","title":"RPS","targets":[{"target":"sumSeries(apps.services.awesome-service.rps)"}]},
+					{"id":2,"type":"graph","title":"Errors","targets":[{"target":"apps.services.awesome-service.errors"}]}
+				]}}`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/other", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`{"dashboard":{"uid":"other","panels":[
+					{"id":1,"type":"graph","title":"RPS","targets":[{"target":"apps.services.other-service.rps"}]}
+				]}}`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/deleted", func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusNotFound)
+				_, _ = rw.Write([]byte(`{"message":"Dashboard not found"}`))
+			})
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("lists panels and queries that reference the prefix", func() {
+			output := bytes.NewBuffer(nil)
+			root.SetOut(output)
+			root.SetArgs([]string{
+				"consumers",
+				"--grafana", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--concurrency", "1",
+				"--no-cache",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(output.String()).To(Equal(
+				"service \t Awesome Service \t Errors \t apps.services.awesome-service.errors \t \n" +
+					"service \t Awesome Service \t RPS \t apps.services.awesome-service.rps \t \n",
+			))
+			Expect(buffer.String()).To(ContainSubstring("1 of 3 dashboards are skipped"))
+		})
+	})
+})
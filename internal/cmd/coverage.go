@@ -1,7 +1,8 @@
 package cmd
 
 import (
-	"net/http"
+	"context"
+	"sort"
 	"time"
 
 	"github.com/c-bata/go-prompt"
@@ -9,26 +10,28 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
-	xtime "go.octolab.org/time"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/history"
 	"github.com/kamilsk/grafaman/internal/model"
 	"github.com/kamilsk/grafaman/internal/presenter"
 	"github.com/kamilsk/grafaman/internal/progress"
 	"github.com/kamilsk/grafaman/internal/provider/grafana"
-	"github.com/kamilsk/grafaman/internal/provider/graphite"
-	"github.com/kamilsk/grafaman/internal/provider/graphite/cache"
 	"github.com/kamilsk/grafaman/internal/repl"
 )
 
 // NewCoverageCommand returns command to calculate metrics coverage by queries.
 func NewCoverageCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
 	var (
-		exclude  []string
-		last     time.Duration
-		noCache  bool
+		options  coverageOptions
+		collapse collapseOptions
+		baseline string
+		policy   string
+		depth    int
+		since    window
+		suggest  bool
 		replMode bool
+		store    bool
 	)
 
 	command := cobra.Command{
@@ -37,22 +40,33 @@ func NewCoverageCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Comman
 		Long:  "Calculates metrics coverage by queries.",
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if config.Grafana.URL == "" {
-				return errors.New("please provide Grafana API endpoint")
+			var modes int
+			for _, enabled := range []bool{baseline != "", policy != "", depth > 0, suggest, since > 0, replMode} {
+				if enabled {
+					modes++
+				}
 			}
-			if config.Grafana.Dashboard == "" {
-				return errors.New("please provide a dashboard unique identifier")
+			if modes > 1 {
+				return errors.New("please use only one of --baseline, --policy, --depth, --suggest, --since and --repl")
 			}
-			if config.Graphite.URL == "" {
-				return errors.New("please provide Graphite API endpoint")
+			if since > 0 && options.version > 0 {
+				return errors.New("please use only one of --version and --since")
 			}
-			if config.Graphite.Prefix == "" {
-				return errors.New("please provide metric prefix")
+			if since > 0 || options.version > 0 {
+				if len(options.files) > 0 || len(options.k8s.manifests) > 0 || options.jsonnet.file != "" {
+					return errors.New("please use --version and --since only with a dashboard from Grafana")
+				}
+				if config.Grafana.Org == allOrgs {
+					return errors.New("please use --version and --since only with a single Grafana organization")
+				}
 			}
-			if prefix := config.Graphite.Prefix; !model.Metric(prefix).Valid() {
-				return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
+			if depth < 0 {
+				return errors.New("please provide a non-negative depth")
 			}
-			return nil
+			if _, err := collapse.cardinality(); err != nil {
+				return err
+			}
+			return validateCoverage(config, options)
 		},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -62,74 +76,189 @@ func NewCoverageCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Comman
 			}
 			printer.SetPrefix(config.Graphite.Prefix)
 
+			fetch := options
+			if suggest {
+				// excluded metrics are needed to avoid them in suggestions
+				fetch.exclude = nil
+			}
+			fetch.history = since > 0
 			indicator := progress.New()
+			metrics, dashboard, err := fetchCoverageData(cmd.Context(), config, fetch, logger, indicator)
+			if err != nil {
+				return err
+			}
+			var excluded model.Metrics
+			if suggest && len(options.exclude) > 0 {
+				matchers := new(model.Queries).Convert(options.exclude).MustMatchers()
+				excluded, metrics = metrics.Filter(matchers...), metrics.Exclude(matchers...)
+			}
 
-			var (
-				metrics   model.Metrics
-				dashboard *model.Dashboard
-			)
-
-			g, ctx := errgroup.WithContext(cmd.Context())
-			g.Go(func() error {
-				var provider cache.Graphite
-				provider, err := graphite.New(config.Graphite.URL, &http.Client{Timeout: config.Graphite.Timeout}, logger, indicator)
-				if err != nil {
-					return err
-				}
-				if !noCache {
-					provider = cache.Decorate(provider, afero.NewOsFs(), logger)
-				}
-
-				metrics, err = provider.Fetch(ctx, config.Graphite.Prefix, last)
-				if err != nil {
-					return err
-				}
-
-				metrics = metrics.Exclude(new(model.Queries).Convert(exclude).MustMatchers()...)
-				return nil
-			})
-			g.Go(func() error {
-				provider, err := grafana.New(config.Grafana.URL, &http.Client{Timeout: config.Grafana.Timeout}, logger, indicator)
+			if since > 0 {
+				metrics := metrics.Filter(config.FilterQuery().MustCompile()).Sort()
+				timeline, err := versionCoverage(
+					cmd.Context(), config, options, metrics, dashboard.Alerts, time.Duration(since), logger, indicator,
+				)
 				if err != nil {
 					return err
 				}
-				dashboard, err = provider.Fetch(ctx, config.Grafana.Dashboard)
-				return err
-			})
-			if err := g.Wait(); err != nil {
-				return err
+				return printer.PrintVersionCoverage(timeline)
 			}
 
-			queries, err := dashboard.Queries(model.Config{
-				SkipRaw:        false,
-				SkipDuplicates: false,
-				NeedSorting:    true,
-				Unpack:         true,
-			})
+			reporter, err := newCoverageReporter(dashboard, options.source)
 			if err != nil {
 				return err
 			}
 
-			reporter := model.NewCoverageReporter(queries)
-
 			if !replMode {
+				cardinality, err := collapse.cardinality()
+				if err != nil {
+					return err
+				}
 				metrics := metrics.Filter(config.FilterQuery().MustCompile()).Sort()
-				return printer.PrintCoverageReport(reporter.CoverageReport(metrics))
+				report := reporter.CoverageReport(metrics)
+				if store {
+					record := model.NewCoverageRecord(config.Graphite.Prefix, sources(options, dashboard), report)
+					record.Exclude, record.Filter = options.exclude, string(config.FilterQuery())
+					if err := history.New(afero.NewOsFs(), logger).Append(record); err != nil {
+						return err
+					}
+				}
+				if suggest {
+					return printer.PrintSuggestions(model.Suggest(report.Uncovered(), excluded))
+				}
+				separate := len(options.k8s.manifests) > 0 && !options.k8s.merge && len(dashboard.Parts) > 1
+				if separate && baseline == "" && policy == "" && depth == 0 {
+					coverage := make([]model.DashboardCoverage, 0, len(dashboard.Parts))
+					for _, part := range dashboard.Parts {
+						reporter, err := newCoverageReporter(part, options.source)
+						if err != nil {
+							return err
+						}
+						coverage = append(coverage, model.NewDashboardCoverage(part.UID, reporter.CoverageReport(metrics)))
+					}
+					return printer.PrintDashboardCoverage(coverage, report.Total())
+				}
+				if collapse.enabled {
+					report = report.Collapse(cardinality)
+				}
+				if baseline != "" {
+					before, err := loadReport(afero.NewOsFs(), baseline)
+					if err != nil {
+						return err
+					}
+					if collapse.enabled {
+						*before = before.Collapse(cardinality)
+					}
+					return printer.PrintCoverageDiff(model.NewCoverageDiff(*before, report))
+				}
+				if policy != "" {
+					result, err := applyPolicy(afero.NewOsFs(), policy, report, printer)
+					if err != nil {
+						return err
+					}
+					if !result.Passed() {
+						return errors.Errorf("policy has %d violation(s)", result.Violations())
+					}
+					return nil
+				}
+				if depth > 0 {
+					return printer.PrintCoverageRollup(report.Rollup(config.Graphite.Prefix, depth), report.Total())
+				}
+				return printer.PrintCoverageReport(report)
 			}
 			metrics.Sort()
+			explainer := model.NewExplainer(dashboard, metrics, new(model.Queries).Convert(options.exclude), "")
 			prompt.New(
-				repl.Prefix(config.Graphite.Prefix, repl.NewCoverageReportExecutor(metrics, reporter, printer, logger)),
+				repl.Command(
+					"explain",
+					repl.Prefix(config.Graphite.Prefix, repl.NewExplainExecutor(explainer, printer, logger)),
+					repl.Prefix(config.Graphite.Prefix, repl.NewCoverageReportExecutor(metrics, reporter, printer, logger)),
+				),
 				repl.NewMetricsCompleter(config.Graphite.Prefix, metrics),
 			).Run()
 			return nil
 		},
 	}
 
+	options.bind(&command)
+	options.bindSource(&command)
+	collapse.bind(&command)
 	flags := command.Flags()
-	flags.StringArrayVar(&exclude, "exclude", nil, "queries to exclude metrics from coverage, e.g. *.median")
-	flags.DurationVar(&last, "last", xtime.Day, "the last interval to fetch")
-	flags.BoolVar(&noCache, "no-cache", false, "disable caching")
 	flags.BoolVar(&replMode, "repl", false, "enable repl mode")
+	flags.BoolVar(&store, "history", false, "append the result to the coverage history")
+	flags.StringVar(&baseline, "baseline", "", "a file with the coverage report to compare with, e.g. produced by coverage -f json")
+	flags.IntVar(&depth, "depth", 0, "roll up the coverage report to subtrees of the specified depth")
+	flags.BoolVar(&suggest, "suggest", false, "suggest queries that would cover uncovered metrics")
+	flags.StringVar(&policy, "policy", "", "a TOML or JSON file with the coverage policy rules")
+	flags.IntVar(&options.version, "version", 0, "a saved version of the dashboard to analyze instead of the latest one")
+	flags.Var(&since, "since", "show coverage by every saved version of the dashboard in the window, e.g. 30d")
 
 	return &command
 }
+
+// versionCoverage calculates coverage by every version of the dashboard saved in the window.
+// The unified alerts are added to every version, they are not versioned with the dashboard.
+func versionCoverage(
+	ctx context.Context,
+	config *cnf.Config,
+	options coverageOptions,
+	metrics model.Metrics,
+	alerts []model.Alert,
+	since time.Duration,
+	logger *logrus.Logger,
+	indicator grafana.ProgressListener,
+) ([]model.VersionCoverage, error) {
+	provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := provider.Versions(ctx, config.Grafana.Dashboard, time.Now().Add(-since))
+	if err != nil {
+		return nil, err
+	}
+
+	// versions are returned from the latest one
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	reports := make([]model.CoverageReport, 0, len(versions))
+	for _, version := range versions {
+		dashboard, err := provider.FetchVersion(ctx, config.Grafana.Dashboard, version.Version)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.Alerts = append(dashboard.Alerts, alerts...)
+		dashboard.Pin(config.Grafana.Variables)
+		reporter, err := newCoverageReporter(dashboard, options.source)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, reporter.CoverageReport(metrics))
+	}
+	return model.NewVersionCoverage(versions, reports), nil
+}
+
+// sources returns unique identifiers of the analyzed dashboards
+// or paths of their files if the dashboards have no identifiers.
+func sources(options coverageOptions, dashboard *model.Dashboard) []string {
+	dashboards := dashboard.Parts
+	if len(dashboards) == 0 {
+		dashboards = []*model.Dashboard{dashboard}
+	}
+	uids := make([]string, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		if dashboard.UID != "" {
+			uids = append(uids, dashboard.UID)
+		}
+	}
+	if len(uids) == len(dashboards) {
+		return uids
+	}
+
+	paths := append(append([]string{}, options.files...), options.k8s.manifests...)
+	if options.jsonnet.file != "" {
+		paths = append(paths, options.jsonnet.file)
+	}
+	if len(paths) == 0 {
+		return uids
+	}
+	return paths
+}
@@ -1,6 +1,12 @@
 package cmd_test
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -50,6 +56,100 @@ var _ = Describe("fetch queries", func() {
 			Expect(buffer.String()).To(ContainSubstring("please provide Graphite API endpoint"))
 		})
 
+		It("does not require Grafana if a dashboard is evaluated from Jsonnet", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-jsonnet", "dashboard.jsonnet",
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Graphite API endpoint"))
+		})
+
+		It("does not require Grafana if a dashboard is read from files", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-file", "testdata/dashboard.json",
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Graphite API endpoint"))
+		})
+
+		It("does not require Grafana if dashboards are read from manifests", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-manifest", "deploy/",
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Graphite API endpoint"))
+		})
+
+		It("returns an error if files are used with Jsonnet", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-file", "testdata/dashboard.json",
+				"--dashboard-jsonnet", "dashboard.jsonnet",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please use only one of --dashboard-file, --dashboard-manifest and --dashboard-jsonnet"))
+		})
+
+		It("returns an error if a version is used with a window", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--version", "3",
+				"--since", "30d",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please use only one of --version and --since"))
+		})
+
+		It("returns an error if a window is invalid", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--since", "month",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring(`invalid duration "month"`))
+		})
+
+		It("returns an error if a version is used without Grafana", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-file", "testdata/dashboard.json",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--version", "3",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please use --version and --since only with a dashboard from Grafana"))
+		})
+
+		It("returns an error if a coverage source is invalid", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--coverage-by", "annotations",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("invalid coverage source: annotations"))
+		})
+
 		It("returns an error if a subset of metrics is omitted", func() {
 			root.SetArgs([]string{
 				"coverage",
@@ -72,7 +172,183 @@ var _ = Describe("fetch queries", func() {
 			Expect(root.Execute()).To(HaveOccurred())
 			Expect(buffer.String()).To(ContainSubstring("invalid metric prefix: $invalid.name"))
 		})
+
+		It("returns an error if a policy is used with a baseline", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--policy", "policy.toml",
+				"--baseline", "old.json",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please use only one of --baseline, --policy, --depth, --suggest, --since and --repl"))
+		})
+
+		It("returns an error if a depth is used with the repl mode", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--depth", "2",
+				"--repl",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please use only one of --baseline, --policy, --depth, --suggest, --since and --repl"))
+		})
 	})
 
-	When("correct usage", func() {})
+	When("correct usage", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics/find", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`[
+					{"id":"apps.services.awesome-service.api.rps","leaf":1},
+					{"id":"apps.services.awesome-service.api.errors","leaf":1},
+					{"id":"apps.services.awesome-service.worker.jobs","leaf":1},
+					{"id":"apps.services.awesome-service.worker.errors","leaf":1}
+				]`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/uid", func(rw http.ResponseWriter, req *http.Request) {
+				defer GinkgoRecover()
+				Fail("the latest version of the dashboard is not needed")
+			})
+			mux.HandleFunc("/api/dashboards/uid/uid/versions", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`[{"version":1,"created":"2999-01-01T00:00:00Z","createdBy":"admin","message":"init"}]`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/uid/versions/1", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`{"version":1,"created":"2999-01-01T00:00:00Z","data":{"panels":[
+					{"id":1,"title":"RPS","type":"graph","targets":[{"target":"apps.services.awesome-service.api.rps"}]}
+				]}}`))
+			})
+			mux.HandleFunc("/api/v1/provisioning/alert-rules", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`[{"uid":"errors","title":"Errors","data":[
+					{"refId":"A","model":{"target":"apps.services.awesome-service.*.errors"}}
+				]}]`))
+			})
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("reports coverage by versions with unified alerts", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--grafana", server.URL,
+				"-d", "uid",
+				"--since", "30d",
+				"--coverage-by", "both",
+				"--graphite", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--no-cache",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(Equal("1 \t 2999-01-01T00:00:00Z \t admin \t init \t 0 \t 0 \t 75.00\n"))
+		})
+
+		It("reports coverage by each dashboard of manifests", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-manifest", "testdata/manifests.yaml",
+				"--graphite", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--no-cache",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(Equal("api \t 1 \t 3 \t 25.00\nworker \t 2 \t 2 \t 50.00\n"))
+		})
+
+		It("writes a report that could be compared by diff", func() {
+			dir, err := ioutil.TempDir("", "grafaman")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			report := func(filename string, args ...string) {
+				buffer.Reset()
+				root = New()
+				root.SetErr(buffer)
+				root.SetOut(buffer)
+				root.SetArgs(append([]string{
+					"coverage",
+					"--dashboard-manifest", "testdata/manifests.yaml",
+					"--dashboard-merge",
+					"--graphite", server.URL,
+					"-m", "apps.services.awesome-service",
+					"--no-cache",
+					"-f", "json",
+				}, args...))
+				Expect(root.Execute()).ToNot(HaveOccurred())
+				Expect(ioutil.WriteFile(filename, buffer.Bytes(), 0644)).To(Succeed())
+			}
+			before, after := filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json")
+			report(before, "--exclude", "*.jobs")
+			report(after)
+
+			buffer.Reset()
+			root = New()
+			root.SetErr(buffer)
+			root.SetOut(buffer)
+			root.SetArgs([]string{"diff", before, after, "-m", "apps.services.awesome-service", "-f", "tsv"})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("worker.jobs"))
+		})
+
+		It("stores identifiers of dashboards of manifests to the history", func() {
+			dir, err := ioutil.TempDir("", "grafaman")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { _ = os.RemoveAll(dir) }()
+			cache, present := os.LookupEnv("XDG_CACHE_HOME")
+			Expect(os.Setenv("XDG_CACHE_HOME", dir)).To(Succeed())
+			defer func() {
+				if present {
+					_ = os.Setenv("XDG_CACHE_HOME", cache)
+					return
+				}
+				_ = os.Unsetenv("XDG_CACHE_HOME")
+			}()
+
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-manifest", "testdata/manifests.yaml",
+				"--graphite", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--history",
+				"--no-cache",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+
+			buffer.Reset()
+			root = New()
+			root.SetErr(buffer)
+			root.SetOut(buffer)
+			root.SetArgs([]string{"history", "-m", "apps.services.awesome-service", "-f", "tsv"})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("api,worker"))
+		})
+
+		It("reports the joint coverage by dashboards of manifests", func() {
+			root.SetArgs([]string{
+				"coverage",
+				"--dashboard-manifest", "testdata/manifests.yaml",
+				"--dashboard-merge",
+				"--graphite", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--no-cache",
+				"-f", "json",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring(`"name":"apps.services.awesome-service.api.errors","hits":0`))
+			Expect(buffer.String()).To(ContainSubstring(`"name":"apps.services.awesome-service.worker.jobs","hits":1`))
+		})
+	})
 })
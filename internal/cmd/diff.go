@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+)
+
+// NewDiffCommand returns command to compare two coverage reports.
+func NewDiffCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	command := cobra.Command{
+		Use:   "diff <old.json> <new.json>",
+		Short: "compares two coverage reports",
+		Long: "Compares two coverage reports produced by coverage -f json. " +
+			"It shows metrics that became covered or uncovered, added or removed, and changes of hits and the total.",
+		Args: cobra.ExactArgs(2),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+			printer.SetPrefix(config.Graphite.Prefix)
+
+			fs := afero.NewOsFs()
+			before, err := loadReport(fs, args[0])
+			if err != nil {
+				return err
+			}
+			after, err := loadReport(fs, args[1])
+			if err != nil {
+				return err
+			}
+			logger.WithField("before", args[0]).WithField("after", args[1]).Info("compare coverage reports")
+
+			return printer.PrintCoverageDiff(model.NewCoverageDiff(*before, *after))
+		},
+	}
+
+	return &command
+}
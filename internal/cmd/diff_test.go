@@ -0,0 +1,41 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kamilsk/grafaman/internal/cmd"
+)
+
+var _ = Describe("compare coverage reports", func() {
+	BeforeEach(func() {
+		buffer.Reset()
+
+		root = New()
+		root.SetErr(buffer)
+		root.SetOut(buffer)
+	})
+
+	When("invalid usage", func() {
+		It("returns an error if a report is omitted", func() {
+			root.SetArgs([]string{"diff", "testdata/old.json"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("accepts 2 arg(s), received 1"))
+		})
+
+		It("returns an error if a report does not exist", func() {
+			root.SetArgs([]string{"diff", "testdata/unknown.json", "testdata/new.json"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("open coverage report"))
+		})
+	})
+
+	When("correct usage", func() {
+		It("prints the difference", func() {
+			root.SetArgs([]string{"diff", "testdata/old.json", "testdata/new.json", "-m", "apps.services.awesome-service"})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("rpc.client.success.ok.percentile.99"))
+			Expect(buffer.String()).To(ContainSubstring("uncovered"))
+		})
+	})
+})
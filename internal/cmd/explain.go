@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/progress"
+)
+
+// NewExplainCommand returns command to explain why a metric is covered or not.
+func NewExplainCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var options coverageOptions
+
+	command := cobra.Command{
+		Use:   "explain <metric>",
+		Short: "explains why a metric is covered or not",
+		Long: "Explains why a metric is covered or not. " +
+			"It lists dashboard queries that match the metric and near misses with a segment failed to match.",
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateCoverage(config, options)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+
+			metrics, dashboard, err := fetchCoverageData(cmd.Context(), config, options, logger, progress.New())
+			if err != nil {
+				return err
+			}
+
+			metric, prefix := args[0], config.Graphite.Prefix
+			if !strings.HasPrefix(metric, prefix+".") {
+				metric = prefix + "." + metric
+			}
+
+			explainer := model.NewExplainer(
+				dashboard,
+				metrics,
+				new(model.Queries).Convert(options.exclude),
+				config.FilterQuery(),
+			)
+			explanation, err := explainer.Explain(model.Metric(metric))
+			if err != nil {
+				return err
+			}
+			return printer.PrintExplanation(explanation)
+		},
+	}
+
+	options.bind(&command)
+
+	return &command
+}
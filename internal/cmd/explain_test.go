@@ -0,0 +1,39 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kamilsk/grafaman/internal/cmd"
+)
+
+var _ = Describe("explain metric coverage", func() {
+	BeforeEach(func() {
+		buffer.Reset()
+
+		root = New()
+		root.SetErr(buffer)
+		root.SetOut(buffer)
+	})
+
+	When("invalid usage", func() {
+		It("returns an error if a metric is omitted", func() {
+			root.SetArgs([]string{"explain"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("accepts 1 arg(s), received 0"))
+		})
+
+		It("returns an error if a Grafana API endpoint is omitted", func() {
+			root.SetArgs([]string{
+				"explain", "rpc.client.success.ok.percentile.75",
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Grafana API endpoint"))
+		})
+	})
+
+	When("correct usage", func() {})
+})
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"go.octolab.org/safe"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/generator"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/progress"
+)
+
+// NewGenerateCommand returns command to generate a dashboard for uncovered metrics.
+func NewGenerateCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		options coverageOptions
+		title   string
+		file    string
+		jsonnet bool
+		panels  bool
+	)
+
+	command := cobra.Command{
+		Use:   "generate",
+		Short: "generates a dashboard for uncovered metrics",
+		Long: "Generates a Grafana dashboard for uncovered metrics. " +
+			"It contains a panel per metric family and could be imported into Grafana.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateCoverage(config, options)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			metrics, dashboard, err := fetchCoverageData(cmd.Context(), config, options, logger, progress.New())
+			if err != nil {
+				return err
+			}
+
+			reporter, err := newCoverageReporter(dashboard, options.source)
+			if err != nil {
+				return err
+			}
+
+			metrics = metrics.Filter(config.FilterQuery().MustCompile()).Sort()
+			report := reporter.CoverageReport(metrics)
+			if title == "" {
+				title = config.Graphite.Prefix + ": uncovered metrics"
+			}
+			result := generator.New(config.Graphite.Prefix, model.DefaultCardinality()).
+				Dashboard(title, report.Uncovered())
+
+			encode := func(output io.Writer) error {
+				fragment := generator.Fragment{Templating: result.Templating, Panels: result.Panels}
+				switch {
+				case jsonnet && panels:
+					return generator.EncodeFragmentJsonnet(output, fragment)
+				case jsonnet:
+					return generator.EncodeJsonnet(output, result)
+				case panels:
+					return generator.Encode(output, fragment)
+				default:
+					return generator.Encode(output, result)
+				}
+			}
+			if file == "" {
+				return encode(cmd.OutOrStdout())
+			}
+
+			f, err := afero.NewOsFs().Create(file)
+			if err != nil {
+				return err
+			}
+			if err := encode(f); err != nil {
+				safe.Close(f, unsafe.Ignore)
+				return err
+			}
+			// the dashboard is not written until the file is closed successfully
+			return f.Close()
+		},
+	}
+
+	options.bind(&command)
+	options.bindSource(&command)
+	flags := command.Flags()
+	flags.StringVar(&title, "title", "", "a title of the dashboard, by default it is based on the metric prefix")
+	flags.BoolVar(&jsonnet, "jsonnet", false, "write Jsonnet code based on grafonnet-lib instead of JSON")
+	flags.BoolVar(&panels, "panels-only", false, "write only panels with their template variables instead of the whole dashboard")
+	flags.StringVar(&file, "file", "", "a file to write the dashboard, by default it is written to stdout")
+
+	return &command
+}
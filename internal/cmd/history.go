@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/history"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+)
+
+// NewHistoryCommand returns command to show coverage over time.
+func NewHistoryCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		changes  bool
+		from, to int
+	)
+
+	command := cobra.Command{
+		Use:   "history",
+		Short: "shows metrics coverage over time",
+		Long:  "Shows metrics coverage over time collected by the coverage command with the --history flag.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if config.Graphite.Prefix == "" {
+				return errors.New("please provide metric prefix")
+			}
+			if prefix := config.Graphite.Prefix; !model.Metric(prefix).Valid() {
+				return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
+			}
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+			printer.SetPrefix(config.Graphite.Prefix)
+
+			records, err := history.New(afero.NewOsFs(), logger).Load(config.Graphite.Prefix)
+			if err != nil {
+				return err
+			}
+
+			if !changes {
+				return printer.PrintCoverageHistory(records)
+			}
+			from, to, err := runs(len(records), from, to)
+			if err != nil {
+				return err
+			}
+			return printer.PrintStatusChanges(records.Changes(from, to))
+		},
+	}
+
+	flags := command.Flags()
+	flags.BoolVar(&changes, "changes", false, "show metrics whose coverage status changed between two runs")
+	flags.IntVar(&from, "from", 0, "the number of the run to compare from; the previous run by default")
+	flags.IntVar(&to, "to", 0, "the number of the run to compare to; the last run by default")
+
+	return &command
+}
+
+// runs converts the numbers of runs into indices of the history;
+// zero values mean the previous and the last runs.
+func runs(total, from, to int) (int, int, error) {
+	if from == 0 {
+		from = total - 1
+	}
+	if to == 0 {
+		to = total
+	}
+	if from < 1 || to < 1 || from > total || to > total {
+		return 0, 0, errors.Errorf("please provide runs between 1 and %d to compare", total)
+	}
+	return from - 1, to - 1, nil
+}
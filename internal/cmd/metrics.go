@@ -23,6 +23,7 @@ import (
 // NewMetricsCommand returns command to fetch metrics from Graphite.
 func NewMetricsCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
 	var (
+		collapse collapseOptions
 		last     time.Duration
 		noCache  bool
 		replMode bool
@@ -43,7 +44,8 @@ func NewMetricsCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command
 			if prefix := config.Graphite.Prefix; !model.Metric(prefix).Valid() {
 				return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
 			}
-			return nil
+			_, err := collapse.cardinality()
+			return err
 		},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -71,6 +73,13 @@ func NewMetricsCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command
 
 			if !replMode {
 				metrics = metrics.Filter(config.FilterQuery().MustCompile()).Sort()
+				if collapse.enabled {
+					cardinality, err := collapse.cardinality()
+					if err != nil {
+						return err
+					}
+					metrics = metrics.Collapse(cardinality)
+				}
 				return printer.PrintMetrics(metrics)
 			}
 			metrics.Sort()
@@ -82,6 +91,7 @@ func NewMetricsCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command
 		},
 	}
 
+	collapse.bind(&command)
 	flags := command.Flags()
 	flags.DurationVar(&last, "last", xtime.Day, "the last interval to fetch")
 	flags.BoolVar(&noCache, "no-cache", false, "disable caching")
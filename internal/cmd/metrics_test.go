@@ -38,6 +38,17 @@ var _ = Describe("fetch queries", func() {
 			Expect(root.Execute()).To(HaveOccurred())
 			Expect(buffer.String()).To(ContainSubstring("invalid metric prefix: $invalid.name"))
 		})
+
+		It("returns an error if a collapse rule is invalid", func() {
+			root.SetArgs([]string{
+				"metrics",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+				"--collapse-rule", "pod=[",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring(`invalid collapse rule "pod"`))
+		})
 	})
 
 	When("correct usage", func() {})
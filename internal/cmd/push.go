@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/generator"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/progress"
+	"github.com/kamilsk/grafaman/internal/provider/grafana"
+)
+
+// NewPushCommand returns command to append panels to a dashboard in Grafana.
+func NewPushCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		options coverageOptions
+		panel   string
+		row     string
+		message string
+		apply   bool
+	)
+
+	command := cobra.Command{
+		Use:   "push",
+		Short: "appends panels for uncovered metrics to a dashboard",
+		Long: "Appends a row of generated panels for uncovered metrics or a given panel to a dashboard. " +
+			"It shows the difference and saves the dashboard only with --apply.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if config.Grafana.URL == "" {
+				return errors.New("please provide Grafana API endpoint")
+			}
+			if config.Grafana.Dashboard == "" {
+				return errors.New("please provide a dashboard unique identifier")
+			}
+			if config.Grafana.Org == allOrgs {
+				return errors.New("please provide a single Grafana organization to push the dashboard")
+			}
+			if panel == "" {
+				return validateCoverage(config, options)
+			}
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+
+			var (
+				panels    interface{}
+				variables []generator.Variable
+				indicator = progress.New()
+			)
+			if panel != "" {
+				data, err := afero.ReadFile(afero.NewOsFs(), panel)
+				if err != nil {
+					return err
+				}
+				if !json.Valid(data) {
+					return errors.Errorf("panel file %q is not a valid JSON", panel)
+				}
+				panels = json.RawMessage(data)
+				// a fragment written by the generate command with --panels-only
+				var fragment struct {
+					Templating *generator.Templating `json:"templating"`
+					Panels     json.RawMessage       `json:"panels"`
+				}
+				if json.Unmarshal(data, &fragment) == nil && fragment.Templating != nil {
+					panels, variables = fragment.Panels, fragment.Templating.List
+				}
+			} else {
+				metrics, dashboard, err := fetchCoverageData(cmd.Context(), config, options, logger, indicator)
+				if err != nil {
+					return err
+				}
+				reporter, err := newCoverageReporter(dashboard, options.source)
+				if err != nil {
+					return err
+				}
+				metrics = metrics.Filter(config.FilterQuery().MustCompile()).Sort()
+				report := reporter.CoverageReport(metrics)
+				uncovered := report.Uncovered()
+				if len(uncovered) == 0 {
+					return errors.New("there are no uncovered metrics to push")
+				}
+				panels, variables = generator.New(config.Graphite.Prefix, model.DefaultCardinality()).Panels(uncovered, 0)
+			}
+
+			if err := resolveOrg(cmd.Context(), config, logger, indicator); err != nil {
+				return err
+			}
+			provider, err := grafana.New(config.Grafana.URL, grafanaClient(config), logger, indicator)
+			if err != nil {
+				return err
+			}
+			source, err := provider.Source(cmd.Context(), config.Grafana.Dashboard)
+			if err != nil {
+				return err
+			}
+			result, err := generator.Append(source.Model, row, panels, variables)
+			if err != nil {
+				return err
+			}
+			if err := printer.PrintStructuralDiff(model.NewStructuralDiff(source.Model, result)); err != nil {
+				return err
+			}
+			if !apply {
+				return nil
+			}
+
+			source.Model = result
+			version, err := provider.Save(cmd.Context(), source, message)
+			if err != nil {
+				return err
+			}
+			cmd.PrintErrf("dashboard %s is saved, version %d\n", config.Grafana.Dashboard, version)
+			return nil
+		},
+	}
+
+	options.bind(&command)
+	options.bindSource(&command)
+	flags := command.Flags()
+	flags.StringVar(&panel, "panel", "", "a file with a panel JSON, an array of panels or a generated fragment to push instead of generated ones")
+	flags.StringVar(&row, "row", "Uncovered metrics", "a title of the row with appended panels")
+	flags.StringVar(&message, "message", "grafaman: add panels for uncovered metrics", "a commit message of the new version")
+	flags.BoolVar(&apply, "apply", false, "save the dashboard, by default only the difference is shown")
+
+	return &command
+}
@@ -0,0 +1,116 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kamilsk/grafaman/internal/cmd"
+)
+
+var _ = Describe("push panels to a dashboard", func() {
+	BeforeEach(func() {
+		buffer.Reset()
+
+		root = New()
+		root.SetErr(buffer)
+		root.SetOut(buffer)
+	})
+
+	When("invalid usage", func() {
+		It("returns an error if a Grafana API endpoint is omitted", func() {
+			root.SetArgs([]string{"push", "-d", "uid", "--panel", "testdata/panel.json"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Grafana API endpoint"))
+		})
+
+		It("returns an error if a dashboard should be pushed to all organizations", func() {
+			root.SetArgs([]string{
+				"push",
+				"--grafana", grafana.URL,
+				"--grafana-org", "all",
+				"-d", "uid",
+				"--panel", "testdata/panel.json",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide a single Grafana organization"))
+		})
+
+		It("returns an error if a Graphite API endpoint is omitted without a panel", func() {
+			root.SetArgs([]string{
+				"push",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Graphite API endpoint"))
+		})
+	})
+
+	When("correct usage", func() {
+		var (
+			server *httptest.Server
+			saved  map[string]interface{}
+		)
+
+		BeforeEach(func() {
+			saved = nil
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/dashboards/uid/uid", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`{"dashboard":{"uid":"uid","version":3,"panels":[
+					{"id":1,"type":"graph","gridPos":{"h":8,"w":12,"x":0,"y":0}}
+				]},"meta":{"folderId":1}}`))
+			})
+			mux.HandleFunc("/api/dashboards/db", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(json.NewDecoder(req.Body).Decode(&saved)).To(Succeed())
+				_, _ = rw.Write([]byte(`{"status":"success","version":4}`))
+			})
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("shows the difference without saving", func() {
+			root.SetArgs([]string{"push", "--grafana", server.URL, "-d", "uid", "--panel", "testdata/panel.json"})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("panels[2]"))
+			Expect(saved).To(BeNil())
+		})
+
+		It("pushes a fragment with template variables", func() {
+			root.SetArgs([]string{
+				"push",
+				"--grafana", server.URL,
+				"-d", "uid",
+				"--panel", "testdata/fragment.json",
+				"--apply",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(saved["dashboard"]).To(HaveKey("templating"))
+			panels := saved["dashboard"].(map[string]interface{})["panels"].([]interface{})
+			Expect(panels).To(HaveLen(3))
+			Expect(panels[2]).To(HaveKeyWithValue("title", "go.<pod>.threads"))
+		})
+
+		It("saves the dashboard with the version", func() {
+			root.SetArgs([]string{
+				"push",
+				"--grafana", server.URL,
+				"-d", "uid",
+				"--panel", "testdata/panel.json",
+				"--apply",
+				"--message", "add a note",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("dashboard uid is saved, version 4"))
+			Expect(saved).To(HaveKeyWithValue("message", "add a note"))
+			Expect(saved["dashboard"]).To(HaveKeyWithValue("version", 3.0))
+		})
+	})
+})
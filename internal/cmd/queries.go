@@ -1,22 +1,26 @@
 package cmd
 
 import (
-	"net/http"
-
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
 	"github.com/kamilsk/grafaman/internal/cnf"
 	"github.com/kamilsk/grafaman/internal/model"
 	"github.com/kamilsk/grafaman/internal/presenter"
 	"github.com/kamilsk/grafaman/internal/progress"
-	"github.com/kamilsk/grafaman/internal/provider/grafana"
+	"github.com/kamilsk/grafaman/internal/provider/file"
 )
 
 // NewQueriesCommand returns command to fetch queries from a Grafana dashboard.
 func NewQueriesCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
-	var cfg model.Config
+	var (
+		cfg     model.Config
+		files   []string
+		links   linkOptions
+		noCache bool
+	)
 
 	command := cobra.Command{
 		Use:   "queries",
@@ -24,11 +28,13 @@ func NewQueriesCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command
 		Long:  "Fetch queries from a Grafana dashboard.",
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if config.Grafana.URL == "" {
-				return errors.New("please provide Grafana API endpoint")
-			}
-			if config.Grafana.Dashboard == "" {
-				return errors.New("please provide a dashboard unique identifier")
+			if len(files) == 0 {
+				if config.Grafana.URL == "" {
+					return errors.New("please provide Grafana API endpoint")
+				}
+				if config.Grafana.Dashboard == "" && config.Grafana.Org != allOrgs {
+					return errors.New("please provide a dashboard unique identifier")
+				}
 			}
 			if prefix := config.Graphite.Prefix; prefix != "" && !model.Metric(prefix).Valid() {
 				return errors.Errorf("invalid metric prefix: %s; it must be simple, e.g. apps.services.name", prefix)
@@ -42,18 +48,27 @@ func NewQueriesCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command
 				return err
 			}
 
-			indicator := progress.New()
-
-			provider, err := grafana.New(config.Grafana.URL, &http.Client{Timeout: config.Grafana.Timeout}, logger, indicator)
-			if err != nil {
-				return err
-			}
-			dashboard, err := provider.Fetch(cmd.Context(), config.Grafana.Dashboard)
-			if err != nil {
-				return err
+			var dashboard *model.Dashboard
+			if len(files) > 0 {
+				var err error
+				dashboard, err = file.New(afero.NewOsFs(), logger).Fetch(cmd.Context(), files)
+				if err != nil {
+					return err
+				}
+			} else {
+				indicator := progress.New()
+				if err := resolveOrg(cmd.Context(), config, logger, indicator); err != nil {
+					return err
+				}
+				var err error
+				dashboard, err = fetchDashboard(cmd.Context(), config, links, noCache, logger, indicator)
+				if err != nil {
+					return err
+				}
 			}
 
 			dashboard.Prefix = config.Graphite.Prefix
+			dashboard.Pin(config.Grafana.Variables)
 			queries, err := dashboard.Queries(cfg)
 			if err != nil {
 				return err
@@ -67,6 +82,10 @@ func NewQueriesCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command
 	flags.BoolVar(&cfg.SkipDuplicates, "allow-duplicates", false, "allow duplicates of queries")
 	flags.BoolVar(&cfg.SkipRaw, "raw", false, "leave the original values of queries")
 	flags.BoolVar(&cfg.NeedSorting, "sort", false, "need to sort queries")
+	flags.BoolVar(&noCache, "no-cache", false, "disable caching")
+	links.bind(&command)
+	flags.StringArrayVar(&files, "dashboard-file", nil,
+		"an exported dashboard JSON file, a glob or a directory to read instead of fetching it from Grafana")
 
 	return &command
 }
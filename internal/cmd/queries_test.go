@@ -1,6 +1,9 @@
 package cmd_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -29,6 +32,12 @@ var _ = Describe("fetch queries", func() {
 			Expect(buffer.String()).To(ContainSubstring("please provide a dashboard unique identifier"))
 		})
 
+		It("returns an error if a dashboard URL is invalid", func() {
+			root.SetArgs([]string{"queries", "-d", "https://grafana.api/dashboards"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("it must contain /d/<uid>"))
+		})
+
 		It("returns an error if a subset of metrics is invalid", func() {
 			root.SetArgs([]string{
 				"queries",
@@ -41,5 +50,98 @@ var _ = Describe("fetch queries", func() {
 		})
 	})
 
-	When("correct usage", func() {})
+	When("correct usage", func() {
+		It("reads queries from an exported dashboard", func() {
+			root.SetArgs([]string{
+				"queries",
+				"--dashboard-file", "testdata/dashboard.json",
+				"-m", "apps.services.awesome-service",
+				"--sort",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(Equal(
+				"apps.services.awesome-service.rpc.client.success.ok.percentile.99\n" +
+					"apps.services.awesome-service.rpc.server.$handler.success.ok.count\n",
+			))
+		})
+
+		It("searches the dashboard in all organizations", func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/orgs", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("X-Grafana-Org-Id")).To(BeEmpty())
+				_, _ = rw.Write([]byte(`[{"id":1,"name":"Main Org."},{"id":2,"name":"Platform"}]`))
+			})
+			mux.HandleFunc("/api/search", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get("dashboardUIDs")).To(Equal("uid"))
+				if req.Header.Get("X-Grafana-Org-Id") != "2" {
+					_, _ = rw.Write([]byte(`[]`))
+					return
+				}
+				_, _ = rw.Write([]byte(`[{"uid":"uid","title":"Awesome Service"}]`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/uid", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("X-Grafana-Org-Id")).To(Equal("2"))
+				_, _ = rw.Write([]byte(`{"dashboard":{"uid":"uid","panels":[
+					{"id":1,"type":"graph","title":"RPS","targets":[{"target":"apps.services.awesome-service.rps"}]}
+				]}}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			root.SetArgs([]string{
+				"queries",
+				"--grafana", server.URL,
+				"--grafana-org", "all",
+				"-d", "uid",
+				"--no-cache",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(Equal("apps.services.awesome-service.rps\n"))
+		})
+
+		It("resolves the organization by its name", func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/orgs", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("X-Grafana-Org-Id")).To(BeEmpty())
+				_, _ = rw.Write([]byte(`[{"id":1,"name":"Main Org."},{"id":2,"name":"Platform"}]`))
+			})
+			mux.HandleFunc("/api/dashboards/uid/uid", func(rw http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("X-Grafana-Org-Id")).To(Equal("2"))
+				_, _ = rw.Write([]byte(`{"dashboard":{"uid":"uid","panels":[]}}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			root.SetArgs([]string{
+				"queries",
+				"--grafana", server.URL,
+				"--grafana-org", "platform",
+				"-d", "uid",
+				"--no-cache",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+
+			buffer.Reset()
+			root = New()
+			root.SetErr(buffer)
+			root.SetOut(buffer)
+			root.SetArgs([]string{
+				"queries",
+				"--grafana", server.URL,
+				"--grafana-org", "unknown",
+				"-d", "uid",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring(`grafana organization "unknown" is not found`))
+		})
+
+		It("returns an error if nothing is found", func() {
+			root.SetArgs([]string{"queries", "--dashboard-file", "testdata/unknown/*.json"})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("file: nothing found by"))
+		})
+	})
 })
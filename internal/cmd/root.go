@@ -33,6 +33,30 @@ func New() *cobra.Command {
 			cnf.WithConfig(config),
 			cnf.WithGraphiteMetrics(),
 		),
+		cnf.Apply(
+			NewCheckCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphite(),
+			cnf.WithOutputFormat(),
+		),
+		cnf.Apply(
+			NewChurnCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphiteMetrics(),
+			cnf.WithOutputFormat(),
+		),
+		cnf.Apply(
+			NewConsumersCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphiteMetrics(),
+			cnf.WithOutputFormat(),
+		),
 		cnf.Apply(
 			NewCoverageCommand(config, logger), viper.New(),
 			cnf.WithConfig(config),
@@ -41,6 +65,35 @@ func New() *cobra.Command {
 			cnf.WithGraphite(),
 			cnf.WithOutputFormat(),
 		),
+		cnf.Apply(
+			NewDiffCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGraphiteMetrics(),
+			cnf.WithOutputFormat(),
+		),
+		cnf.Apply(
+			NewExplainCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphite(),
+			cnf.WithOutputFormat(),
+		),
+		cnf.Apply(
+			NewGenerateCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphite(),
+		),
+		cnf.Apply(
+			NewHistoryCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGraphiteMetrics(),
+			cnf.WithOutputFormat(),
+		),
 		cnf.Apply(
 			NewMetricsCommand(config, logger), viper.New(),
 			cnf.WithConfig(config),
@@ -48,6 +101,14 @@ func New() *cobra.Command {
 			cnf.WithGraphite(),
 			cnf.WithOutputFormat(),
 		),
+		cnf.Apply(
+			NewPushCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphite(),
+			cnf.WithOutputFormat(),
+		),
 		cnf.Apply(
 			NewQueriesCommand(config, logger), viper.New(),
 			cnf.WithConfig(config),
@@ -55,6 +116,14 @@ func New() *cobra.Command {
 			cnf.WithGraphiteMetrics(),
 			cnf.WithOutputFormat(),
 		),
+		cnf.Apply(
+			NewTargetsCommand(config, logger), viper.New(),
+			cnf.WithConfig(config),
+			cnf.WithDebug(config, logger),
+			cnf.WithGrafana(),
+			cnf.WithGraphite(),
+			cnf.WithOutputFormat(),
+		),
 	)
 
 	return &command
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/presenter"
+	"github.com/kamilsk/grafaman/internal/progress"
+)
+
+// NewTargetsCommand returns command to calculate queries coverage by metrics.
+func NewTargetsCommand(config *cnf.Config, logger *logrus.Logger) *cobra.Command {
+	var (
+		options  coverageOptions
+		deadOnly bool
+	)
+
+	command := cobra.Command{
+		Use:   "targets",
+		Short: "calculates queries coverage by metrics",
+		Long: "Calculates queries coverage by metrics. " +
+			"Queries of panels that match no metrics are flagged as dead with titles of their panels. " +
+			"Queries of annotations and alerts are not reported. Excluded metrics still count as existing ones, " +
+			"so a query that matches only them is not dead.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateCoverage(config, options)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := new(presenter.Printer)
+			if err := printer.SetOutput(cmd.OutOrStdout()).SetFormat(config.Output.Format); err != nil {
+				return err
+			}
+
+			fetch := options
+			// excluded metrics still exist, so queries that match them are not dead
+			fetch.exclude = nil
+			metrics, dashboard, err := fetchCoverageData(cmd.Context(), config, fetch, logger, progress.New())
+			if err != nil {
+				return err
+			}
+
+			dashboard.Prefix = config.Graphite.Prefix
+			targets, err := dashboard.Targets(model.Config{NeedSorting: true, Unpack: true})
+			if err != nil {
+				return err
+			}
+
+			report := model.NewQueryReport(targets, metrics)
+			if deadOnly {
+				report = report.OnlyDead()
+			}
+			return printer.PrintQueryReport(report)
+		},
+	}
+
+	options.bind(&command)
+	flags := command.Flags()
+	flags.BoolVar(&deadOnly, "dead-only", false, "show only queries of panels that match no metrics")
+
+	return &command
+}
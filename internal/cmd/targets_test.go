@@ -0,0 +1,81 @@
+package cmd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kamilsk/grafaman/internal/cmd"
+)
+
+var _ = Describe("calculate queries coverage", func() {
+	BeforeEach(func() {
+		buffer.Reset()
+
+		root = New()
+		root.SetErr(buffer)
+		root.SetOut(buffer)
+	})
+
+	When("invalid usage", func() {
+		It("returns an error if a Grafana API endpoint is omitted", func() {
+			root.SetArgs([]string{
+				"targets",
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "apps.services.awesome-service",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("please provide Grafana API endpoint"))
+		})
+
+		It("returns an error if a subset of metrics is invalid", func() {
+			root.SetArgs([]string{
+				"targets",
+				"--grafana", grafana.URL,
+				"-d", "uid",
+				"--graphite", graphite.URL,
+				"-m", "$invalid.name",
+			})
+			Expect(root.Execute()).To(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("invalid metric prefix: $invalid.name"))
+		})
+	})
+
+	When("correct usage", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics/find", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(`[
+					{"id":"apps.services.awesome-service.api.rps","leaf":1},
+					{"id":"apps.services.awesome-service.worker.jobs","leaf":1}
+				]`))
+			})
+			server = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("does not report queries of excluded metrics as dead", func() {
+			root.SetArgs([]string{
+				"targets",
+				"--dashboard-manifest", "testdata/manifests.yaml",
+				"--dashboard-merge",
+				"--graphite", server.URL,
+				"-m", "apps.services.awesome-service",
+				"--exclude", "*.rps",
+				"--no-cache",
+				"--dead-only",
+				"-f", "tsv",
+			})
+			Expect(root.Execute()).ToNot(HaveOccurred())
+			Expect(buffer.String()).To(BeEmpty())
+		})
+	})
+})
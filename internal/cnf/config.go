@@ -1,9 +1,12 @@
 package cnf
 
 import (
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/kamilsk/grafaman/internal/model"
 )
 
@@ -12,9 +15,11 @@ type Config struct {
 	App     string `mapstructure:"app"`
 	File    string `mapstructure:"-"`
 	Grafana struct {
-		URL       string        `mapstructure:"grafana"`
-		Dashboard string        `mapstructure:"dashboard"`
-		Timeout   time.Duration `mapstructure:"grafana_timeout"`
+		URL       string              `mapstructure:"grafana"`
+		Dashboard string              `mapstructure:"dashboard"`
+		Org       string              `mapstructure:"grafana_org"`
+		Variables map[string][]string `mapstructure:"-"`
+		Timeout   time.Duration       `mapstructure:"grafana_timeout"`
 	} `mapstructure:",squash"`
 	Graphite struct {
 		URL     string        `mapstructure:"graphite"`
@@ -32,6 +37,51 @@ type Config struct {
 	} `mapstructure:"output"`
 }
 
+// ResolveDashboardURL extracts the Grafana endpoint, the dashboard unique identifier,
+// the organization and values of variables from the dashboard URL
+// if it is provided instead of the identifier, e.g.
+//
+//	https://grafana.api/d/DTknF4rik/awesome-service?orgId=2&var-env=prod
+//
+// The explicitly provided endpoint and organization take precedence.
+func (config *Config) ResolveDashboardURL() error {
+	dashboard := config.Grafana.Dashboard
+	if !strings.Contains(dashboard, "://") {
+		return nil
+	}
+
+	u, err := url.Parse(dashboard)
+	if err != nil {
+		return errors.Wrapf(err, "invalid dashboard URL %q", dashboard)
+	}
+	i := strings.Index(u.Path, "/d/")
+	if i < 0 {
+		return errors.Errorf("invalid dashboard URL %q; it must contain /d/<uid>", dashboard)
+	}
+	uid := strings.SplitN(u.Path[i+len("/d/"):], "/", 2)[0]
+	if uid == "" {
+		return errors.Errorf("invalid dashboard URL %q; it must contain /d/<uid>", dashboard)
+	}
+
+	config.Grafana.Dashboard = uid
+	if config.Grafana.URL == "" {
+		config.Grafana.URL = (&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host, Path: u.Path[:i]}).String()
+	}
+	query := u.Query()
+	if org := query.Get("orgId"); org != "" && config.Grafana.Org == "" {
+		config.Grafana.Org = org
+	}
+	for key, values := range query {
+		if name := strings.TrimPrefix(key, "var-"); name != key && name != "" {
+			if config.Grafana.Variables == nil {
+				config.Grafana.Variables = make(map[string][]string)
+			}
+			config.Grafana.Variables[name] = values
+		}
+	}
+	return nil
+}
+
 // FilterQuery returns a Query to filter metrics.
 func (config *Config) FilterQuery() model.Query {
 	filter, prefix := config.Graphite.Filter, config.Graphite.Prefix
@@ -36,3 +36,40 @@ func TestConfig_FilterQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_ResolveDashboardURL(t *testing.T) {
+	t.Run("unique identifier", func(t *testing.T) {
+		var config Config
+		config.Grafana.Dashboard = "DTknF4rik"
+		require.NoError(t, config.ResolveDashboardURL())
+		assert.Equal(t, "DTknF4rik", config.Grafana.Dashboard)
+		assert.Empty(t, config.Grafana.URL)
+	})
+
+	t.Run("full URL", func(t *testing.T) {
+		var config Config
+		config.Grafana.Dashboard = "https://grafana.api/grafana/d/DTknF4rik/awesome?orgId=2&var-env=prod&var-dc=msk&var-dc=spb&from=now-6h"
+		require.NoError(t, config.ResolveDashboardURL())
+		assert.Equal(t, "DTknF4rik", config.Grafana.Dashboard)
+		assert.Equal(t, "https://grafana.api/grafana", config.Grafana.URL)
+		assert.Equal(t, "2", config.Grafana.Org)
+		assert.Equal(t, map[string][]string{"env": {"prod"}, "dc": {"msk", "spb"}}, config.Grafana.Variables)
+	})
+
+	t.Run("explicit endpoint and organization", func(t *testing.T) {
+		var config Config
+		config.Grafana.URL = "https://grafana.internal/"
+		config.Grafana.Org = "1"
+		config.Grafana.Dashboard = "https://grafana.api/d/DTknF4rik?orgId=2"
+		require.NoError(t, config.ResolveDashboardURL())
+		assert.Equal(t, "DTknF4rik", config.Grafana.Dashboard)
+		assert.Equal(t, "https://grafana.internal/", config.Grafana.URL)
+		assert.Equal(t, "1", config.Grafana.Org)
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		var config Config
+		config.Grafana.Dashboard = "https://grafana.api/dashboards"
+		assert.Error(t, config.ResolveDashboardURL())
+	})
+}
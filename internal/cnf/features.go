@@ -18,8 +18,8 @@ var Features = config.Features{
 	},
 	{
 		Name:    "grafonnet",
-		Enabled: false,
-		Brief:   "Declare dashboard as code.",
+		Enabled: true,
+		Brief:   "Generate and evaluate dashboards as code.",
 		Docs:    "https://www.notion.so/octolab/Grafonnet-3cd366ab76e146db82fd28f520bbdf68?r=0b753cbf767346f5a6fd51194829a2f3",
 	},
 	{
@@ -44,6 +44,9 @@ func WithConfig(config *Config) xcobra.Option {
 			}
 
 			fn.Must(func() error { return container.Unmarshal(config) })
+			if err := config.ResolveDashboardURL(); err != nil {
+				return err
+			}
 
 			// ad hoc
 			if config.Graphite.Prefix == "" && config.App != "" {
@@ -102,8 +105,9 @@ func WithGrafana() xcobra.Option {
 	return func(command *cobra.Command, container *viper.Viper) {
 		flags := command.Flags()
 		flags.String("grafana", "", "Grafana API endpoint")
-		flags.StringP("dashboard", "d", "", "a dashboard unique identifier")
+		flags.StringP("dashboard", "d", "", "a dashboard unique identifier or URL")
 		flags.Duration("grafana-timeout", time.Second, "timeout duration for Grafana API requests")
+		flags.String("grafana-org", "", "a Grafana organization identifier or name, or \"all\" to search every accessible one")
 
 		container.RegisterAlias("grafana", "grafana_url")
 		container.RegisterAlias("dashboard", "grafana_dashboard")
@@ -115,6 +119,8 @@ func WithGrafana() xcobra.Option {
 			func() error { return container.BindPFlag("grafana_dashboard", flags.Lookup("dashboard")) },
 			func() error { return container.BindEnv("grafana_timeout", "GRAFANA_TIMEOUT") },
 			func() error { return container.BindPFlag("grafana_timeout", flags.Lookup("grafana-timeout")) },
+			func() error { return container.BindEnv("grafana_org", "GRAFANA_ORG") },
+			func() error { return container.BindPFlag("grafana_org", flags.Lookup("grafana-org")) },
 		)
 	}
 }
@@ -79,7 +79,10 @@ func TestWithConfig(t *testing.T) {
 		box.RegisterAlias("metrics", "graphite_metrics")
 
 		cmd = Apply(cmd, box, WithConfig(&cnf))
+		wd, err := os.Getwd()
+		require.NoError(t, err)
 		require.NoError(t, os.Chdir("testdata"))
+		defer func() { require.NoError(t, os.Chdir(wd)) }()
 		assert.NoError(t, cmd.PreRunE(cmd, nil))
 		assert.NotEqual(t, src, cnf)
 		assert.Equal(t, "awesome-service", cnf.App)
@@ -0,0 +1,41 @@
+package cnf
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// LoadPolicy reads coverage policy from a TOML or JSON file, e.g.
+//
+//	[[rules]]
+//	match = "apps.services.*.rpc.server.*.errors"
+//	requirement = "required"
+//	min_hits = 1
+//	reason = "every server error must be on a dashboard"
+func LoadPolicy(fs afero.Fs, filename string) (model.Policy, error) {
+	var policy model.Policy
+
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return policy, errors.Wrap(err, "policy: read file")
+	}
+
+	switch ext := filepath.Ext(filename); ext {
+	case ".json":
+		err = json.Unmarshal(data, &policy)
+	case ".toml":
+		err = toml.Unmarshal(data, &policy)
+	default:
+		return policy, errors.Errorf("policy: unsupported file extension %q, only .json and .toml are supported", ext)
+	}
+	if err != nil {
+		return policy, errors.Wrapf(err, "policy: decode file %q", filename)
+	}
+	return policy, policy.Validate()
+}
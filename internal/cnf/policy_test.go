@@ -0,0 +1,72 @@
+package cnf_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/cnf"
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	expected := model.Policy{Rules: []model.Rule{
+		{
+			Match:       "apps.services.*.rpc.server.*.errors",
+			Requirement: model.Required,
+			MinHits:     1,
+			Reason:      "every server error must be on a dashboard",
+		},
+		{
+			Match:       "apps.services.*.go.*",
+			Requirement: model.Optional,
+			Reason:      "runtime metrics",
+		},
+	}}
+
+	t.Run("toml", func(t *testing.T) {
+		policy, err := LoadPolicy(afero.NewOsFs(), "testdata/policy.toml")
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "policy.json", []byte(`{"rules":[
+			{"match":"apps.services.*.rpc.server.*.errors","requirement":"required","min_hits":1,
+			 "reason":"every server error must be on a dashboard"},
+			{"match":"apps.services.*.go.*","requirement":"optional","reason":"runtime metrics"}
+		]}`), 0644))
+
+		policy, err := LoadPolicy(fs, "policy.json")
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "policy.yaml", nil, 0644))
+
+		_, err := LoadPolicy(fs, "policy.yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("singular key", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "policy.toml", []byte("[[rule]]\nmatch = \"*\"\nrequirement = \"required\"\n"), 0644))
+
+		_, err := LoadPolicy(fs, "policy.toml")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "[[rules]]")
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "policy.json", []byte(`{"rules":[{"match":"*"}]}`), 0644))
+
+		_, err := LoadPolicy(fs, "policy.json")
+		assert.Error(t, err)
+	})
+}
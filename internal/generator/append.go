@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Append adds a row with the panels and missing template variables
+// to a copy of the raw dashboard model. The panels could be anything
+// encoded into a JSON object or an array of objects. They get unique
+// identifiers and are placed below existing ones keeping their layout.
+// A variable which is already present must have the same type and query,
+// otherwise the panels would query other series than expected.
+func Append(
+	dashboard map[string]interface{},
+	title string,
+	panels interface{},
+	variables []Variable,
+) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := convert(dashboard, &result); err != nil {
+		return nil, err
+	}
+	var appended []interface{}
+	if err := convert(panels, &appended); err != nil {
+		var panel map[string]interface{}
+		if convert(panels, &panel) != nil {
+			return nil, errors.Wrap(err, "generator: panels must be an object or an array of objects")
+		}
+		appended = []interface{}{panel}
+	}
+
+	existing, _ := result["panels"].([]interface{})
+	var id, bottom float64
+	var walk func(panels []interface{}, nested bool)
+	walk = func(panels []interface{}, nested bool) {
+		for _, raw := range panels {
+			panel, _ := raw.(map[string]interface{})
+			if current, _ := panel["id"].(float64); current > id {
+				id = current
+			}
+			if position, _ := panel["gridPos"].(map[string]interface{}); !nested && position != nil {
+				y, _ := position["y"].(float64)
+				h, _ := position["h"].(float64)
+				if y+h > bottom {
+					bottom = y + h
+				}
+			}
+			children, _ := panel["panels"].([]interface{})
+			walk(children, true)
+		}
+	}
+	walk(existing, false)
+
+	id++
+	existing = append(existing, map[string]interface{}{
+		"id":        id,
+		"type":      "row",
+		"title":     title,
+		"collapsed": false,
+		"gridPos":   map[string]interface{}{"h": 1.0, "w": float64(gridWidth), "x": 0.0, "y": bottom},
+		"panels":    []interface{}{},
+	})
+	bottom++
+
+	top := -1.0
+	for _, raw := range appended {
+		panel, is := raw.(map[string]interface{})
+		if !is {
+			return nil, errors.New("generator: panels must be an object or an array of objects")
+		}
+		position, _ := panel["gridPos"].(map[string]interface{})
+		if position == nil {
+			position = map[string]interface{}{"h": float64(panelHeight), "w": float64(panelWidth), "x": 0.0, "y": 0.0}
+			panel["gridPos"] = position
+		}
+		if y, _ := position["y"].(float64); top < 0 || y < top {
+			top = y
+		}
+	}
+	for _, raw := range appended {
+		panel := raw.(map[string]interface{})
+		position := panel["gridPos"].(map[string]interface{})
+		y, _ := position["y"].(float64)
+		position["y"] = y - top + bottom
+		id++
+		panel["id"] = id
+		existing = append(existing, panel)
+	}
+	result["panels"] = existing
+
+	templating, _ := result["templating"].(map[string]interface{})
+	if templating == nil {
+		templating = make(map[string]interface{})
+		result["templating"] = templating
+	}
+	list, _ := templating["list"].([]interface{})
+	for _, variable := range variables {
+		var present bool
+		for _, raw := range list {
+			current, _ := raw.(map[string]interface{})
+			if current["name"] != variable.Name {
+				continue
+			}
+			if current["type"] != variable.Type || current["query"] != variable.Query {
+				return nil, errors.Errorf(
+					"generator: variable %q of the dashboard has type %v and query %v instead of %q and %q",
+					variable.Name, current["type"], current["query"], variable.Type, variable.Query,
+				)
+			}
+			present = true
+			break
+		}
+		if present {
+			continue
+		}
+		var converted interface{}
+		if err := convert(variable, &converted); err != nil {
+			return nil, err
+		}
+		list = append(list, converted)
+	}
+	templating["list"] = list
+
+	return result, nil
+}
+
+// convert makes a deep copy of the value in a JSON compatible form.
+func convert(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return errors.Wrap(err, "generator: encode value")
+	}
+	return errors.Wrap(json.Unmarshal(data, out), "generator: decode value")
+}
@@ -0,0 +1,82 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/generator"
+)
+
+func TestAppend(t *testing.T) {
+	var dashboard map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "Awesome service",
+		"version": 7,
+		"panels": [
+			{"id": 1, "type": "graph", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0}},
+			{"id": 2, "type": "row", "collapsed": true, "gridPos": {"h": 1, "w": 24, "x": 0, "y": 8},
+			 "panels": [{"id": 5, "type": "graph", "gridPos": {"h": 8, "w": 12, "x": 0, "y": 9}}]}
+		],
+		"templating": {"list": [{"name": "datasource", "type": "datasource", "query": "graphite"}]}
+	}`), &dashboard))
+
+	t.Run("generated panels", func(t *testing.T) {
+		panels := []Panel{
+			{ID: 1, Type: "stat", GridPos: GridPos{H: 8, W: 8, X: 0, Y: 0}},
+			{ID: 2, Type: "stat", GridPos: GridPos{H: 8, W: 8, X: 0, Y: 8}},
+		}
+		variables := []Variable{
+			{Name: "datasource", Type: "datasource", Query: "graphite"},
+			{Name: "prefix", Type: "constant", Query: "apps.services.awesome-service"},
+		}
+
+		result, err := Append(dashboard, "Uncovered metrics", panels, variables)
+		require.NoError(t, err)
+		assert.Len(t, dashboard["panels"], 2, "the origin must not be changed")
+
+		list := result["panels"].([]interface{})
+		require.Len(t, list, 5)
+		row := list[2].(map[string]interface{})
+		assert.Equal(t, "row", row["type"])
+		assert.Equal(t, 6.0, row["id"])
+		assert.Equal(t, 9.0, row["gridPos"].(map[string]interface{})["y"])
+		assert.Equal(t, 7.0, list[3].(map[string]interface{})["id"])
+		assert.Equal(t, 10.0, list[3].(map[string]interface{})["gridPos"].(map[string]interface{})["y"])
+		assert.Equal(t, 18.0, list[4].(map[string]interface{})["gridPos"].(map[string]interface{})["y"])
+		assert.Equal(t, 7.0, result["version"])
+		assert.Len(t, result["templating"].(map[string]interface{})["list"], 2)
+	})
+
+	t.Run("given panel", func(t *testing.T) {
+		panel := json.RawMessage(`{"type": "text", "title": "Note"}`)
+
+		result, err := Append(dashboard, "Notes", panel, nil)
+		require.NoError(t, err)
+
+		list := result["panels"].([]interface{})
+		require.Len(t, list, 4)
+		assert.Equal(t, "Note", list[3].(map[string]interface{})["title"])
+		assert.Equal(t, 7.0, list[3].(map[string]interface{})["id"])
+	})
+
+	t.Run("conflicting variable", func(t *testing.T) {
+		var conflicting map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"templating": {"list": [{"name": "prefix", "type": "constant", "query": "apps.services.another-service"}]}
+		}`), &conflicting))
+		variables := []Variable{{Name: "prefix", Type: "constant", Query: "apps.services.awesome-service"}}
+
+		result, err := Append(conflicting, "Uncovered metrics", []Panel{{ID: 1, Type: "stat"}}, variables)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `variable "prefix"`)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid panels", func(t *testing.T) {
+		_, err := Append(dashboard, "Invalid", json.RawMessage(`"panel"`), nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// A Dashboard represents a Grafana dashboard model ready for import.
+type Dashboard struct {
+	UID           string     `json:"uid,omitempty"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Editable      bool       `json:"editable"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          Range      `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+// A Fragment contains panels with template variables they use
+// to add them to an existing dashboard.
+type Fragment struct {
+	Templating Templating `json:"templating"`
+	Panels     []Panel    `json:"panels"`
+}
+
+// A Range represents a time range of the dashboard.
+type Range struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// A Templating contains template variables of the dashboard.
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// A Variable represents a template variable.
+type Variable struct {
+	Name       string `json:"name"`
+	Label      string `json:"label,omitempty"`
+	Type       string `json:"type"`
+	Datasource string `json:"datasource,omitempty"`
+	Query      string `json:"query"`
+	Multi      bool   `json:"multi,omitempty"`
+	IncludeAll bool   `json:"includeAll,omitempty"`
+	Refresh    int    `json:"refresh,omitempty"`
+	Hide       int    `json:"hide,omitempty"`
+}
+
+// A Panel represents a dashboard panel.
+type Panel struct {
+	ID         int                    `json:"id"`
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Datasource string                 `json:"datasource,omitempty"`
+	GridPos    GridPos                `json:"gridPos"`
+	Targets    []Target               `json:"targets,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+// A GridPos represents a panel position on the dashboard grid.
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// A Target represents a Graphite query of the panel.
+type Target struct {
+	RefID  string `json:"refId"`
+	Target string `json:"target"`
+}
+
+// Encode writes the dashboard or its fragment as indented JSON.
+func Encode(output io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return errors.Wrap(encoder.Encode(value), "generator: encode dashboard")
+}
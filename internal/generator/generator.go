@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// Grid settings of generated panels.
+const (
+	gridWidth   = 24
+	panelWidth  = 8
+	panelHeight = 8
+)
+
+// Kinds of panels chosen by the metric suffix.
+const (
+	kindCounter    = "counter"
+	kindGauge      = "gauge"
+	kindPercentile = "percentile"
+)
+
+var (
+	counters = map[string]bool{
+		"calls": true, "count": true, "counter": true, "errors": true, "fail": true,
+		"hits": true, "ok": true, "requests": true, "sum": true, "total": true,
+	}
+	percentiles = map[string]bool{"percentile": true, "percentiles": true, "quantile": true}
+	percentile  = regexp.MustCompile(`^(p|percentile_?)\d{2,3}$`)
+	placeholder = regexp.MustCompile(`^<(\w+)>$`)
+)
+
+// New returns a generator of panels for metrics under the prefix.
+// Dynamic segments of metrics are detected by the cardinality
+// and replaced with template variables.
+func New(prefix string, cardinality model.Cardinality) *generator {
+	return &generator{prefix: prefix, cardinality: cardinality}
+}
+
+type generator struct {
+	prefix      string
+	cardinality model.Cardinality
+}
+
+// Dashboard builds a dashboard with a panel per metric family.
+func (generator *generator) Dashboard(title string, metrics model.Metrics) Dashboard {
+	panels, variables := generator.Panels(metrics, 0)
+	return Dashboard{
+		Title:         title,
+		Tags:          []string{"grafaman"},
+		Editable:      true,
+		SchemaVersion: 27,
+		Time:          Range{From: "now-6h", To: "now"},
+		Templating:    Templating{List: variables},
+		Panels:        panels,
+	}
+}
+
+// Panels builds a panel per metric family starting from the row y of the grid.
+// Percentiles of the same family are combined into a single panel.
+// It also returns template variables used by the panels.
+func (generator *generator) Panels(metrics model.Metrics, y int) ([]Panel, []Variable) {
+	variables := []Variable{
+		{Name: "datasource", Label: "Datasource", Type: "datasource", Query: "graphite"},
+		{Name: "prefix", Type: "constant", Query: generator.prefix, Hide: 2},
+	}
+	names := make(map[string]string)
+	variable := func(name, query string) string {
+		if current, present := names[query]; present {
+			return current
+		}
+		unique := name
+		for i := 2; ; i++ {
+			var taken bool
+			for _, v := range variables {
+				if v.Name == unique {
+					taken = true
+					break
+				}
+			}
+			if !taken {
+				break
+			}
+			unique = name + "_" + strconv.Itoa(i)
+		}
+		names[query] = unique
+		variables = append(variables, Variable{
+			Name:       unique,
+			Type:       "query",
+			Datasource: "$datasource",
+			Query:      query,
+			Multi:      true,
+			IncludeAll: true,
+			Refresh:    1,
+		})
+		return unique
+	}
+
+	type group struct {
+		kind, title, query string
+		values             []string
+	}
+	var (
+		groups []*group
+		index  = make(map[string]*group)
+	)
+	for _, family := range metrics.Families(generator.cardinality) {
+		name := strings.TrimPrefix(strings.TrimPrefix(string(family.Name), generator.prefix), ".")
+		segments := strings.Split(name, ".")
+		query := make([]string, 0, len(segments)+1)
+		query = append(query, "$prefix")
+		for _, segment := range segments {
+			if match := placeholder.FindStringSubmatch(segment); match != nil {
+				segment = "$" + variable(match[1], strings.Join(append(query, "*"), "."))
+			}
+			query = append(query, segment)
+		}
+
+		last := segments[len(segments)-1]
+		if len(segments) > 1 && (percentile.MatchString(last) || percentiles[segments[len(segments)-2]]) {
+			parent := strings.Join(query[:len(query)-1], ".")
+			if g, present := index[parent]; present {
+				g.values = append(g.values, query[len(query)-1])
+				continue
+			}
+			g := &group{
+				kind:   kindPercentile,
+				title:  strings.Join(segments[:len(segments)-1], "."),
+				query:  parent,
+				values: []string{query[len(query)-1]},
+			}
+			index[parent] = g
+			groups = append(groups, g)
+			continue
+		}
+
+		kind := kindGauge
+		if counters[last] {
+			kind = kindCounter
+		}
+		groups = append(groups, &group{kind: kind, title: name, query: strings.Join(query, ".")})
+	}
+
+	panels := make([]Panel, 0, len(groups))
+	for i, g := range groups {
+		panel := Panel{
+			ID:         i + 1,
+			Title:      g.title,
+			Datasource: "$datasource",
+			GridPos: GridPos{
+				H: panelHeight,
+				W: panelWidth,
+				X: i * panelWidth % gridWidth,
+				Y: y + i*panelWidth/gridWidth*panelHeight,
+			},
+		}
+		switch g.kind {
+		case kindCounter:
+			panel.Type = "timeseries"
+			panel.Title += " rate"
+			panel.Targets = []Target{{RefID: "A", Target: "perSecond(" + g.query + ")"}}
+		case kindPercentile:
+			query := g.query + "." + g.values[0]
+			if len(g.values) > 1 {
+				query = g.query + ".{" + strings.Join(g.values, ",") + "}"
+			}
+			panel.Type = "timeseries"
+			panel.Targets = []Target{{RefID: "A", Target: "aliasByNode(" + query + ", -1)"}}
+		default:
+			panel.Type = "stat"
+			panel.Targets = []Target{{RefID: "A", Target: g.query}}
+			panel.Options = map[string]interface{}{
+				"reduceOptions": map[string]interface{}{"calcs": []string{"lastNotNull"}},
+			}
+		}
+		panels = append(panels, panel)
+	}
+	return panels, variables
+}
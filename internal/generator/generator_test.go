@@ -0,0 +1,108 @@
+package generator_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/generator"
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerator_Dashboard(t *testing.T) {
+	metrics := model.Metrics{
+		"apps.services.awesome-service.go.pod-5dbdcd5dbb-6z58f.threads",
+		"apps.services.awesome-service.go.pod-7f9c6b8d4c-x2k9p.threads",
+		"apps.services.awesome-service.rpc.client.success.ok",
+		"apps.services.awesome-service.rpc.client.success.ok.percentile.75",
+		"apps.services.awesome-service.rpc.client.success.ok.percentile.99",
+		"apps.services.awesome-service.worker.queue.size",
+	}
+	dashboard := New("apps.services.awesome-service", model.DefaultCardinality()).
+		Dashboard("awesome-service: uncovered metrics", metrics)
+
+	output := bytes.NewBuffer(nil)
+	require.NoError(t, Encode(output, dashboard))
+
+	file := "testdata/dashboard.json"
+	if *update {
+		require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+	}
+
+	golden, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), output.String())
+}
+
+func TestEncodeJsonnet(t *testing.T) {
+	metrics := model.Metrics{
+		"apps.services.awesome-service.go.pod-5dbdcd5dbb-6z58f.threads",
+		"apps.services.awesome-service.rpc.client.success.ok",
+		"apps.services.awesome-service.rpc.client.success.ok.percentile.75",
+		"apps.services.awesome-service.rpc.client.success.ok.percentile.99",
+	}
+	generator := New("apps.services.awesome-service", model.DefaultCardinality())
+
+	t.Run("dashboard", func(t *testing.T) {
+		output := bytes.NewBuffer(nil)
+		require.NoError(t, EncodeJsonnet(output, generator.Dashboard("awesome-service: uncovered metrics", metrics)))
+
+		file := "testdata/dashboard.jsonnet"
+		if *update {
+			require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+		}
+
+		golden, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, string(golden), output.String())
+	})
+
+	t.Run("panels", func(t *testing.T) {
+		panels, variables := generator.Panels(metrics, 0)
+		output := bytes.NewBuffer(nil)
+		require.NoError(t, EncodeFragmentJsonnet(output, Fragment{Templating: Templating{List: variables}, Panels: panels}))
+
+		file := "testdata/panels.jsonnet"
+		if *update {
+			require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+		}
+
+		golden, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, string(golden), output.String())
+	})
+}
+
+func TestGenerator_Panels(t *testing.T) {
+	metrics := model.Metrics{"app.a.count", "app.b.count", "app.c.count", "app.d.count"}
+	panels, variables := New("app", model.Cardinality{}).Panels(metrics, 10)
+	require.Len(t, panels, 4)
+	assert.Len(t, variables, 2)
+	assert.Equal(t, GridPos{H: 8, W: 8, X: 16, Y: 10}, panels[2].GridPos)
+	assert.Equal(t, GridPos{H: 8, W: 8, X: 0, Y: 18}, panels[3].GridPos)
+	assert.Equal(t, "perSecond($prefix.d.count)", panels[3].Targets[0].Target)
+}
+
+func TestGenerator_Panels_percentiles(t *testing.T) {
+	metrics := model.Metrics{
+		"app.http.status.200",
+		"app.http.status.404",
+		"app.http.status.500",
+		"app.latency.p95",
+		"app.latency.p99",
+		"app.timing.percentile.75",
+	}
+	panels, _ := New("app", model.Cardinality{}).Panels(metrics, 0)
+	require.Len(t, panels, 5)
+	assert.Equal(t, "$prefix.http.status.200", panels[0].Targets[0].Target)
+	assert.Equal(t, "$prefix.http.status.404", panels[1].Targets[0].Target)
+	assert.Equal(t, "$prefix.http.status.500", panels[2].Targets[0].Target)
+	assert.Equal(t, "aliasByNode($prefix.latency.{p95,p99}, -1)", panels[3].Targets[0].Target)
+	assert.Equal(t, "aliasByNode($prefix.timing.percentile.75, -1)", panels[4].Targets[0].Target)
+}
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const jsonnetHeader = `local grafana = import 'grafonnet/grafana.libsonnet';
+local dashboard = grafana.dashboard;
+local graphPanel = grafana.graphPanel;
+local graphite = grafana.graphite;
+local statPanel = grafana.statPanel;
+local template = grafana.template;
+
+`
+
+// EncodeJsonnet writes the dashboard as Jsonnet code based on grafonnet-lib constructors.
+// Documentation: https://github.com/grafana/grafonnet-lib.
+func EncodeJsonnet(output io.Writer, dashboard Dashboard) error {
+	code := new(strings.Builder)
+	code.WriteString(jsonnetHeader)
+	fmt.Fprintf(code, "dashboard.new(\n  %s,\n  tags=%s,\n  editable=%t,\n  time_from=%s,\n  time_to=%s,\n  schemaVersion=%d,\n",
+		literal(dashboard.Title), literal(dashboard.Tags), dashboard.Editable,
+		literal(dashboard.Time.From), literal(dashboard.Time.To), dashboard.SchemaVersion)
+	if dashboard.UID != "" {
+		fmt.Fprintf(code, "  uid=%s,\n", literal(dashboard.UID))
+	}
+	code.WriteString(")\n")
+	for _, variable := range dashboard.Templating.List {
+		fmt.Fprintf(code, ".addTemplate(%s)\n", templateCode(variable))
+	}
+	for _, panel := range dashboard.Panels {
+		fmt.Fprintf(code, ".addPanel(\n  %s,\n  gridPos=%s,\n)\n", panelCode(panel, "  "), gridPosCode(panel.GridPos))
+	}
+
+	_, err := io.WriteString(output, code.String())
+	return errors.Wrap(err, "generator: encode dashboard as jsonnet")
+}
+
+// EncodeFragmentJsonnet writes the fragment as a Jsonnet object with templates and panels arrays
+// based on grafonnet-lib constructors, e.g. to pass them to addTemplates and addPanels.
+func EncodeFragmentJsonnet(output io.Writer, fragment Fragment) error {
+	code := new(strings.Builder)
+	code.WriteString(jsonnetHeader)
+	code.WriteString("{\n  templates: [\n")
+	for _, variable := range fragment.Templating.List {
+		fmt.Fprintf(code, "    %s,\n", templateCode(variable))
+	}
+	code.WriteString("  ],\n  panels: [\n")
+	for _, panel := range fragment.Panels {
+		fmt.Fprintf(code, "    %s\n    + { gridPos: %s },\n", panelCode(panel, "    "), gridPosCode(panel.GridPos))
+	}
+	code.WriteString("  ],\n}\n")
+
+	_, err := io.WriteString(output, code.String())
+	return errors.Wrap(err, "generator: encode fragment as jsonnet")
+}
+
+func templateCode(variable Variable) string {
+	switch variable.Type {
+	case "datasource":
+		code := fmt.Sprintf("template.datasource(%s, %s, null", literal(variable.Name), literal(variable.Query))
+		if variable.Label != "" {
+			code += ", label=" + literal(variable.Label)
+		}
+		return code + ")"
+	case "query":
+		code := fmt.Sprintf("template.new(%s, %s, %s", literal(variable.Name), literal(variable.Datasource), literal(variable.Query))
+		if variable.Label != "" {
+			code += ", label=" + literal(variable.Label)
+		}
+		if variable.IncludeAll {
+			code += ", includeAll=true"
+		}
+		if variable.Multi {
+			code += ", multi=true"
+		}
+		if variable.Refresh == 1 {
+			code += ", refresh='load'"
+		}
+		return code + ")"
+	default:
+		return literal(variable)
+	}
+}
+
+func panelCode(panel Panel, indent string) string {
+	datasource := "null"
+	if panel.Datasource != "" {
+		datasource = literal(panel.Datasource)
+	}
+
+	var code string
+	switch panel.Type {
+	case "stat":
+		code = fmt.Sprintf("statPanel.new(%s, datasource=%s, reducerFunction='lastNotNull')", literal(panel.Title), datasource)
+	case "timeseries", "graph":
+		code = fmt.Sprintf("graphPanel.new(%s, datasource=%s)", literal(panel.Title), datasource)
+	default:
+		return literal(panel)
+	}
+	for _, target := range panel.Targets {
+		code += fmt.Sprintf("\n%s.addTarget(graphite.target(%s))", indent, literal(target.Target))
+	}
+	return code
+}
+
+func gridPosCode(position GridPos) string {
+	return fmt.Sprintf("{ h: %d, w: %d, x: %d, y: %d }", position.H, position.W, position.X, position.Y)
+}
+
+// literal returns the value as JSON which is valid Jsonnet.
+func literal(value interface{}) string {
+	code := new(strings.Builder)
+	encoder := json.NewEncoder(code)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "null"
+	}
+	return strings.TrimSuffix(code.String(), "\n")
+}
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"go.octolab.org/safe"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// New returns a coverage history store based on JSON lines.
+func New(fs afero.Fs, logger *logrus.Logger) *store {
+	return &store{fs, logger}
+}
+
+// Filename returns history file name. The history is long-lived,
+// so it is kept in the user cache directory instead of the temporary one.
+func Filename(prefix string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "grafaman", prefix) + ".grafaman.history.jsonl"
+}
+
+type store struct {
+	fs     afero.Fs
+	logger *logrus.Logger
+}
+
+// Append adds the record to the end of the history.
+func (store *store) Append(record model.CoverageRecord) error {
+	filename := Filename(record.Prefix)
+	logger := store.logger.WithFields(logrus.Fields{"component": "history", "file": filename})
+	if err := store.fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		logger.WithError(err).Error("prepare storage")
+		return errors.Wrap(err, "history: prepare storage")
+	}
+	file, err := store.fs.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		logger.WithError(err).Error("prepare storage")
+		return errors.Wrap(err, "history: prepare storage")
+	}
+	defer safe.Close(file, func(err error) { logger.WithError(err).Warning("flush data") })
+
+	if err := json.NewEncoder(file).Encode(&record); err != nil {
+		logger.WithError(err).Error("store data")
+		return errors.Wrap(err, "history: store data")
+	}
+
+	logger.Info("store data to history")
+	return nil
+}
+
+// Load returns the whole history of the prefix in chronological order.
+func (store *store) Load(prefix string) (model.CoverageHistory, error) {
+	filename := Filename(prefix)
+	logger := store.logger.WithFields(logrus.Fields{"component": "history", "file": filename})
+	file, err := store.fs.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logger.Info("history is empty")
+			return nil, nil
+		}
+		logger.WithError(err).Error("prepare storage")
+		return nil, errors.Wrap(err, "history: prepare storage")
+	}
+	defer safe.Close(file, func(err error) { logger.WithError(err).Warning("release data") })
+
+	var history model.CoverageHistory
+	decoder := json.NewDecoder(file)
+	for {
+		var record model.CoverageRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			logger.WithError(err).Error("decode data")
+			return nil, errors.Wrap(err, "history: decode data")
+		}
+		history = append(history, record)
+	}
+	return history, nil
+}
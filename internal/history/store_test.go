@@ -0,0 +1,67 @@
+package history_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/history"
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestStore(t *testing.T) {
+	prefix := "test"
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	t.Run("empty history", func(t *testing.T) {
+		store := New(afero.NewMemMapFs(), logger)
+
+		history, err := store.Load(prefix)
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("append and load", func(t *testing.T) {
+		store := New(afero.NewMemMapFs(), logger)
+
+		var first, second model.CoverageReport
+		first.Add("test.metric.a", 1)
+		first.Add("test.metric.b", 0)
+		second.Add("test.metric.a", 0)
+		second.Add("test.metric.b", 2)
+
+		require.NoError(t, store.Append(model.NewCoverageRecord(prefix, []string{"uid"}, first)))
+		require.NoError(t, store.Append(model.NewCoverageRecord(prefix, []string{"uid"}, second)))
+
+		history, err := store.Load(prefix)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, first.Metrics, history[0].Report.Metrics)
+		assert.Equal(t, second.Metrics, history[1].Report.Metrics)
+		assert.Equal(t, []string{"uid"}, history[1].Dashboards)
+		assert.Equal(t, 50.0, history[1].Total)
+	})
+
+	t.Run("decode invalid data", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, Filename(prefix), []byte("invalid"), 0644))
+
+		history, err := New(fs, logger).Load(prefix)
+		assert.Error(t, err)
+		assert.Nil(t, history)
+	})
+}
+
+func TestFilename(t *testing.T) {
+	filename := Filename("test")
+	assert.Equal(t, "test.grafaman.history.jsonl", filepath.Base(filename))
+	assert.Equal(t, "grafaman", filepath.Base(filepath.Dir(filename)))
+	assert.Equal(t, ".jsonl", filepath.Ext(filename))
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultFanOut is the number of children with descendants
+// after which they are treated as dynamic.
+const DefaultFanOut = 50
+
+// A SegmentRule replaces metric segments matched by the pattern
+// with the <name> placeholder.
+type SegmentRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// A Cardinality defines how to detect dynamic segments of metrics.
+// A segment is dynamic if it matches one of the rules or its parent
+// has more than FanOut children with descendants, they are replaced
+// with the <id> placeholder. The zero FanOut disables the check.
+type Cardinality struct {
+	Rules  []SegmentRule
+	FanOut int
+}
+
+// DefaultCardinality returns rules for well-known generated identifiers,
+// such as UUIDs, IPs, Kubernetes pods and hashes.
+func DefaultCardinality() Cardinality {
+	return Cardinality{
+		Rules: []SegmentRule{
+			{Name: "uuid", Pattern: regexp.MustCompile(`^[0-9a-fA-F]{8}(-?[0-9a-fA-F]{4}){3}-?[0-9a-fA-F]{12}$`)},
+			{Name: "ip", Pattern: regexp.MustCompile(`^\d{1,3}([_-]\d{1,3}){3}$`)},
+			// a pod of a Deployment: the name, the ReplicaSet hash and the random suffix
+			// in the alphabet of Kubernetes without vowels
+			{Name: "pod", Pattern: regexp.MustCompile(
+				`^[a-z0-9]([-a-z0-9]*[a-z0-9])?-[bcdfghjklmnpqrstvwxz2-9]{8,10}-[bcdfghjklmnpqrstvwxz2-9]{5}$`,
+			)},
+			{Name: "hash", Pattern: regexp.MustCompile(`^[0-9a-f]{12,}$`)},
+		},
+		FanOut: DefaultFanOut,
+	}
+}
+
+// A MetricFamily groups metrics that differ only by dynamic segments.
+type MetricFamily struct {
+	Name    Metric  `json:"name"`
+	Metrics Metrics `json:"metrics"`
+}
+
+// Families groups the metrics by families.
+// It keeps the order of the first metric of each family.
+func (metrics Metrics) Families(cardinality Cardinality) []MetricFamily {
+	index := make(map[Metric]int)
+	families := make([]MetricFamily, 0, len(metrics))
+	for i, path := range cardinality.normalize(metrics) {
+		name := Metric(strings.Join(path, "."))
+		if j, present := index[name]; present {
+			families[j].Metrics = append(families[j].Metrics, metrics[i])
+			continue
+		}
+		index[name] = len(families)
+		families = append(families, MetricFamily{Name: name, Metrics: Metrics{metrics[i]}})
+	}
+	return families
+}
+
+// Collapse replaces metrics of the same family by the family name.
+func (metrics Metrics) Collapse(cardinality Cardinality) Metrics {
+	families := metrics.Families(cardinality)
+	collapsed := make(Metrics, 0, len(families))
+	for _, family := range families {
+		collapsed = append(collapsed, family.Name)
+	}
+	return collapsed
+}
+
+// Collapse replaces metrics of the same family by the family name.
+// The family has the minimal hits of its metrics, so it is covered
+// only if all its metrics are covered.
+func (report *CoverageReport) Collapse(cardinality Cardinality) CoverageReport {
+	metrics := make(Metrics, 0, len(report.Metrics))
+	hits := make(map[Metric]int, len(report.Metrics))
+	for _, hit := range report.Metrics {
+		metrics = append(metrics, Metric(hit.Metric))
+		hits[Metric(hit.Metric)] = hit.Hits
+	}
+
+	var collapsed CoverageReport
+	for _, family := range metrics.Families(cardinality) {
+		min := hits[family.Metrics[0]]
+		for _, metric := range family.Metrics[1:] {
+			if hits[metric] < min {
+				min = hits[metric]
+			}
+		}
+		collapsed.Add(family.Name, min)
+	}
+	return collapsed
+}
+
+func (cardinality Cardinality) normalize(metrics Metrics) [][]string {
+	paths := make([][]string, 0, len(metrics))
+	group := make([]int, 0, len(metrics))
+	for i, metric := range metrics {
+		segments := strings.Split(string(metric), ".")
+		for j, segment := range segments {
+			for _, rule := range cardinality.Rules {
+				if rule.Pattern.MatchString(segment) {
+					segments[j] = "<" + rule.Name + ">"
+					break
+				}
+			}
+		}
+		paths = append(paths, segments)
+		group = append(group, i)
+	}
+	if cardinality.FanOut > 0 {
+		cardinality.fanOut(paths, group, 0)
+	}
+	return paths
+}
+
+// fanOut replaces segments at the depth with the <id> placeholder
+// if there are too many of them with descendants in the group.
+func (cardinality Cardinality) fanOut(paths [][]string, group []int, depth int) {
+	children := make(map[string][]int)
+	order := make([]string, 0, 8)
+	for _, i := range group {
+		if len(paths[i]) <= depth+1 {
+			continue
+		}
+		segment := paths[i][depth]
+		if _, present := children[segment]; !present {
+			order = append(order, segment)
+		}
+		children[segment] = append(children[segment], i)
+	}
+
+	if len(order) > cardinality.FanOut {
+		var merged []int
+		for _, segment := range order {
+			for _, i := range children[segment] {
+				paths[i][depth] = "<id>"
+			}
+			merged = append(merged, children[segment]...)
+		}
+		children, order = map[string][]int{"<id>": merged}, []string{"<id>"}
+	}
+	for _, segment := range order {
+		cardinality.fanOut(paths, children[segment], depth+1)
+	}
+}
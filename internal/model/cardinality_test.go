@@ -0,0 +1,90 @@
+package model_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestMetrics_Families(t *testing.T) {
+	cardinality := DefaultCardinality()
+
+	t.Run("rules", func(t *testing.T) {
+		metrics := Metrics{
+			"app.go.pod-5dbdcd5dbb-6z58f.threads",
+			"app.go.pod-7f9c6b8d4c-x2k9p.threads",
+			"app.rpc-client-total",
+			"app.host.10_0_0_1.cpu",
+			"app.job.123e4567-e89b-12d3-a456-426614174000.done",
+			"app.build.3f2a9c1d7e5b.ok",
+		}
+		assert.Equal(t, []MetricFamily{
+			{Name: "app.go.<pod>.threads", Metrics: Metrics{
+				"app.go.pod-5dbdcd5dbb-6z58f.threads",
+				"app.go.pod-7f9c6b8d4c-x2k9p.threads",
+			}},
+			{Name: "app.rpc-client-total", Metrics: Metrics{"app.rpc-client-total"}},
+			{Name: "app.host.<ip>.cpu", Metrics: Metrics{"app.host.10_0_0_1.cpu"}},
+			{Name: "app.job.<uuid>.done", Metrics: Metrics{"app.job.123e4567-e89b-12d3-a456-426614174000.done"}},
+			{Name: "app.build.<hash>.ok", Metrics: Metrics{"app.build.3f2a9c1d7e5b.ok"}},
+		}, metrics.Families(cardinality))
+	})
+
+	t.Run("fan-out", func(t *testing.T) {
+		cardinality := Cardinality{FanOut: 2}
+		metrics := Metrics{"app.user.a.hits", "app.user.b.hits", "app.user.c.hits", "app.user.total", "app.rpc.x.ok"}
+		assert.Equal(t, Metrics{"app.user.<id>.hits", "app.user.total", "app.rpc.x.ok"}, metrics.Collapse(cardinality))
+	})
+
+	t.Run("without rules", func(t *testing.T) {
+		metrics := make(Metrics, 0, 10)
+		for i := 0; i < 10; i++ {
+			metrics = append(metrics, Metric(fmt.Sprintf("app.node%d.ok", i)))
+		}
+		assert.Equal(t, metrics, metrics.Collapse(Cardinality{}))
+	})
+}
+
+func TestCoverageReport_Collapse(t *testing.T) {
+	var report CoverageReport
+	report.Add("app.go.pod-5dbdcd5dbb-6z58f.threads", 1)
+	report.Add("app.go.pod-7f9c6b8d4c-x2k9p.threads", 0)
+	report.Add("app.rpc.ok", 2)
+
+	var expected CoverageReport
+	expected.Add("app.go.<pod>.threads", 0)
+	expected.Add("app.rpc.ok", 2)
+	assert.Equal(t, expected, report.Collapse(DefaultCardinality()))
+}
+
+func TestDefaultCardinality(t *testing.T) {
+	rules := make(map[string]*regexp.Regexp)
+	for _, rule := range DefaultCardinality().Rules {
+		rules[rule.Name] = rule.Pattern
+	}
+
+	t.Run("pod", func(t *testing.T) {
+		for _, segment := range []string{
+			"pod-5dbdcd5dbb-6z58f",
+			"awesome-service-7f9c6b8d4c-x2k9p",
+			"worker-84d7c9fbb-qwz2n",
+		} {
+			assert.True(t, rules["pod"].MatchString(segment), segment)
+		}
+		for _, segment := range []string{
+			"api-v1-users",
+			"http-status-404-count",
+			"rpc-client-5xx-total",
+			"grpc-v2-calls",
+			"kube-controller-manager",
+			"pod-5dbdcd5dbb-6z58",
+			"pod-5dbdcd5dba-6z58f",
+		} {
+			assert.False(t, rules["pod"].MatchString(segment), segment)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package model
+
+import "fmt"
+
+// A CoverageCheck contains the result of the coverage report
+// verification against thresholds and a baseline.
+type CoverageCheck struct {
+	Total     float64  `json:"total"`
+	Minimum   float64  `json:"minimum"`
+	Baseline  *float64 `json:"baseline,omitempty"`
+	Uncovered Metrics  `json:"uncovered"`
+	Failures  []string `json:"failures,omitempty"`
+}
+
+// Check verifies the report by the minimum coverage and the baseline report if it is present.
+// Newly uncovered metrics are metrics without hits that were covered by the baseline
+// or are absent in it. Without the baseline all metrics without hits are newly uncovered.
+func (report *CoverageReport) Check(minimum float64, baseline *CoverageReport) CoverageCheck {
+	check := CoverageCheck{Total: report.Total(), Minimum: minimum, Uncovered: Metrics{}}
+
+	covered := make(map[string]bool)
+	if baseline != nil {
+		total := baseline.Total()
+		check.Baseline = &total
+		for _, hit := range baseline.Metrics {
+			covered[hit.Metric] = hit.Hits > 0
+		}
+	}
+	for _, hit := range report.Metrics {
+		if hit.Hits > 0 {
+			continue
+		}
+		if was, present := covered[hit.Metric]; !present || was {
+			check.Uncovered = append(check.Uncovered, Metric(hit.Metric))
+		}
+	}
+
+	if check.Total < minimum {
+		check.Failures = append(check.Failures,
+			fmt.Sprintf("coverage %.2f%% is below the minimum %.2f%%", check.Total, minimum))
+	}
+	if check.Baseline != nil && check.Total < *check.Baseline {
+		check.Failures = append(check.Failures,
+			fmt.Sprintf("coverage %.2f%% dropped from the baseline %.2f%%", check.Total, *check.Baseline))
+	}
+	return check
+}
+
+// Passed returns true if the check has no failures.
+func (check CoverageCheck) Passed() bool {
+	return len(check.Failures) == 0
+}
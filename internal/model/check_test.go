@@ -0,0 +1,42 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestCoverageReport_Check(t *testing.T) {
+	var baseline, report CoverageReport
+	baseline.Add("metric.a", 1)
+	baseline.Add("metric.b", 1)
+	baseline.Add("metric.c", 0)
+	report.Add("metric.a", 1)
+	report.Add("metric.b", 0)
+	report.Add("metric.c", 0)
+	report.Add("metric.d", 0)
+
+	t.Run("without baseline", func(t *testing.T) {
+		check := report.Check(20, nil)
+		assert.True(t, check.Passed())
+		assert.Nil(t, check.Baseline)
+		assert.Equal(t, Metrics{"metric.b", "metric.c", "metric.d"}, check.Uncovered)
+	})
+
+	t.Run("below the minimum", func(t *testing.T) {
+		check := report.Check(50, nil)
+		assert.False(t, check.Passed())
+		assert.Equal(t, []string{"coverage 25.00% is below the minimum 50.00%"}, check.Failures)
+	})
+
+	t.Run("dropped from the baseline", func(t *testing.T) {
+		check := report.Check(0, &baseline)
+		assert.False(t, check.Passed())
+		require.NotNil(t, check.Baseline)
+		assert.Equal(t, Metrics{"metric.b", "metric.d"}, check.Uncovered)
+		assert.Equal(t, []string{"coverage 25.00% dropped from the baseline 66.67%"}, check.Failures)
+	})
+}
@@ -0,0 +1,79 @@
+package model
+
+// A MetricChurn contains metrics added and removed between two snapshots.
+type MetricChurn struct {
+	Added   Metrics            `json:"added"`
+	Removed Metrics            `json:"removed"`
+	Stale   map[Metric]Queries `json:"stale,omitempty"`
+}
+
+// NewMetricChurn compares two snapshots of metrics.
+func NewMetricChurn(before, after Metrics) MetricChurn {
+	churn := MetricChurn{Added: Metrics{}, Removed: Metrics{}}
+
+	registry := make(map[Metric]struct{}, len(before))
+	for _, metric := range before {
+		registry[metric] = struct{}{}
+	}
+	for _, metric := range after {
+		if _, present := registry[metric]; present {
+			delete(registry, metric)
+			continue
+		}
+		churn.Added = append(churn.Added, metric)
+	}
+	for _, metric := range before {
+		if _, present := registry[metric]; present {
+			churn.Removed = append(churn.Removed, metric)
+		}
+	}
+
+	churn.Added.Sort()
+	churn.Removed.Sort()
+	return churn
+}
+
+// Reference finds queries that still reference removed metrics.
+func (churn *MetricChurn) Reference(queries Queries) {
+	churn.Stale = make(map[Metric]Queries)
+	for _, query := range queries {
+		matcher := query.MustCompile()
+		for _, metric := range churn.Removed {
+			if matcher.Match(string(metric)) {
+				churn.Stale[metric] = append(churn.Stale[metric], query)
+			}
+		}
+	}
+}
+
+// Groups splits the churn by subtrees of the specified depth after the prefix.
+func (churn MetricChurn) Groups(prefix string, depth int) []ChurnGroup {
+	index := make(map[string]int)
+	groups := make([]ChurnGroup, 0, 8)
+	group := func(metric Metric) *ChurnGroup {
+		subtree := metric.Subtree(prefix, depth)
+		if i, present := index[subtree]; present {
+			return &groups[i]
+		}
+		index[subtree] = len(groups)
+		groups = append(groups, ChurnGroup{Subtree: subtree})
+		return &groups[len(groups)-1]
+	}
+
+	for _, metric := range churn.Added {
+		g := group(metric)
+		g.Added = append(g.Added, metric)
+	}
+	for _, metric := range churn.Removed {
+		g := group(metric)
+		g.Removed = append(g.Removed, metric)
+	}
+	return groups
+}
+
+// A ChurnGroup contains metrics added and removed in a subtree.
+type ChurnGroup struct {
+	Subtree string  `json:"subtree"`
+	Added   Metrics `json:"added,omitempty"`
+	Removed Metrics `json:"removed,omitempty"`
+}
@@ -0,0 +1,29 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestMetricChurn(t *testing.T) {
+	before := Metrics{"app.rpc.a", "app.rpc.b", "app.go.pod-1.threads"}
+	after := Metrics{"app.rpc.a", "app.rpc.c", "app.go.pod-2.threads"}
+
+	churn := NewMetricChurn(before, after)
+	assert.Equal(t, Metrics{"app.go.pod-2.threads", "app.rpc.c"}, churn.Added)
+	assert.Equal(t, Metrics{"app.go.pod-1.threads", "app.rpc.b"}, churn.Removed)
+
+	churn.Reference(Queries{"app.rpc.*", "app.go.*.threads", "app.http.*"})
+	assert.Equal(t, map[Metric]Queries{
+		"app.go.pod-1.threads": {"app.go.*.threads"},
+		"app.rpc.b":            {"app.rpc.*"},
+	}, churn.Stale)
+
+	assert.Equal(t, []ChurnGroup{
+		{Subtree: "go", Added: Metrics{"app.go.pod-2.threads"}, Removed: Metrics{"app.go.pod-1.threads"}},
+		{Subtree: "rpc", Added: Metrics{"app.rpc.c"}, Removed: Metrics{"app.rpc.b"}},
+	}, churn.Groups("app", 1))
+}
@@ -0,0 +1,54 @@
+package model
+
+import "sort"
+
+// A Consumer represents a query of a dashboard panel that references metrics.
+type Consumer struct {
+	Dashboard DashboardRef `json:"dashboard"`
+	Panel     string       `json:"panel"`
+	Query     Query        `json:"query"`
+}
+
+// Consumers represent a slice of queries that reference metrics.
+type Consumers []Consumer
+
+// NewConsumers returns consumers of the dashboard by its transformed queries.
+func NewConsumers(dashboard DashboardRef, targets Targets) Consumers {
+	consumers := make(Consumers, 0, len(targets))
+	for _, target := range targets {
+		for _, panel := range target.Panels {
+			consumers = append(consumers, Consumer{Dashboard: dashboard, Panel: panel, Query: target.Query})
+		}
+	}
+	return consumers
+}
+
+// Dashboards returns the number of unique dashboards.
+func (consumers Consumers) Dashboards() int {
+	registry := make(map[string]struct{}, len(consumers))
+	for _, consumer := range consumers {
+		registry[consumer.Dashboard.Org+"/"+consumer.Dashboard.UID] = struct{}{}
+	}
+	return len(registry)
+}
+
+// Sort sorts consumers by dashboards, panels and queries.
+func (consumers Consumers) Sort() Consumers {
+	sort.SliceStable(consumers, func(i, j int) bool {
+		a, b := consumers[i], consumers[j]
+		if a.Dashboard.Title != b.Dashboard.Title {
+			return a.Dashboard.Title < b.Dashboard.Title
+		}
+		if a.Dashboard.Org != b.Dashboard.Org {
+			return a.Dashboard.Org < b.Dashboard.Org
+		}
+		if a.Dashboard.UID != b.Dashboard.UID {
+			return a.Dashboard.UID < b.Dashboard.UID
+		}
+		if a.Panel != b.Panel {
+			return a.Panel < b.Panel
+		}
+		return a.Query < b.Query
+	})
+	return consumers
+}
@@ -0,0 +1,38 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestNewConsumers(t *testing.T) {
+	dashboard := &Dashboard{
+		Prefix: "apps.services.awesome-service",
+		RawData: []Query{
+			"sumSeries(apps.services.awesome-service.rps)",
+			"apps.services.other-service.rps",
+			"apps.services.awesome-service.errors",
+		},
+		Panels: []Panel{
+			{Title: "RPS", RawData: []Query{"sumSeries(apps.services.awesome-service.rps)", "apps.services.other-service.rps"}},
+			{Title: "Errors", RawData: []Query{"apps.services.awesome-service.errors", "apps.services.awesome-service.rps"}},
+		},
+	}
+	targets, err := dashboard.Targets(Config{NeedSorting: true, Unpack: true})
+	require.NoError(t, err)
+
+	first := DashboardRef{UID: "first", Title: "Awesome Service"}
+	second := DashboardRef{UID: "second", Title: "Awesome Overview", Org: "Platform"}
+	consumers := append(NewConsumers(first, targets), NewConsumers(second, targets[:1])...).Sort()
+	assert.Equal(t, Consumers{
+		{Dashboard: second, Panel: "Errors", Query: "apps.services.awesome-service.errors"},
+		{Dashboard: first, Panel: "Errors", Query: "apps.services.awesome-service.errors"},
+		{Dashboard: first, Panel: "Errors", Query: "apps.services.awesome-service.rps"},
+		{Dashboard: first, Panel: "RPS", Query: "apps.services.awesome-service.rps"},
+	}, consumers)
+	assert.Equal(t, 2, consumers.Dashboards())
+}
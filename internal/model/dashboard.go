@@ -1,7 +1,9 @@
 package model
 
 import (
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-graphite/carbonapi/pkg/parser"
 	"github.com/pkg/errors"
@@ -17,46 +19,96 @@ type Config struct {
 
 // A Dashboard represents Grafana dashboard.
 type Dashboard struct {
-	Prefix    string
-	RawData   []Query
-	Variables []Variable
+	UID         string
+	Version     int
+	Updated     time.Time
+	Prefix      string
+	RawData     []Query
+	Panels      []Panel
+	Annotations []Annotation
+	Alerts      []Alert
+	Links       []string       // unique identifiers of linked dashboards
+	Libraries   map[string]int // versions of used library panels by their unique identifiers
+	Variables   []Variable
+	Parts       []*Dashboard `json:"-"` // merged dashboards
+}
+
+// A DashboardVersion represents a saved version of a Grafana dashboard.
+type DashboardVersion struct {
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"author"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// A DashboardRef represents a dashboard found in Grafana.
+type DashboardRef struct {
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	URL    string `json:"url,omitempty"`
+	Folder string `json:"folder,omitempty"`
+	Org    string `json:"org,omitempty"` // filled if dashboards of several organizations are searched
+}
+
+// An Org represents a Grafana organization.
+type Org struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// A DashboardSource contains the raw JSON model of a Grafana dashboard
+// and its folder to modify and save it back.
+type DashboardSource struct {
+	Model    map[string]interface{}
+	FolderID int
+}
+
+// MergeDashboards combines queries, panels and variables of the dashboards
+// to process them as a single one. Variables with the same name are merged
+// by their options.
+func MergeDashboards(dashboards ...*Dashboard) *Dashboard {
+	if len(dashboards) == 1 {
+		return dashboards[0]
+	}
+
+	merged := new(Dashboard)
+	uids := make([]string, 0, len(dashboards))
+	index := make(map[string]int)
+	for _, dashboard := range dashboards {
+		uids = append(uids, dashboard.UID)
+		if dashboard.Updated.After(merged.Updated) {
+			merged.Updated = dashboard.Updated
+		}
+		merged.RawData = append(merged.RawData, dashboard.RawData...)
+		merged.Panels = append(merged.Panels, dashboard.Panels...)
+		merged.Annotations = append(merged.Annotations, dashboard.Annotations...)
+		merged.Alerts = append(merged.Alerts, dashboard.Alerts...)
+		merged.Links = append(merged.Links, dashboard.Links...)
+		for _, variable := range dashboard.Variables {
+			i, present := index[variable.Name]
+			if !present {
+				index[variable.Name] = len(merged.Variables)
+				merged.Variables = append(merged.Variables, Variable{Name: variable.Name})
+				i = len(merged.Variables) - 1
+			}
+			merged.Variables[i].Options = append(merged.Variables[i].Options, variable.Options...)
+		}
+	}
+	merged.UID = strings.Join(uids, ",")
+	merged.Parts = dashboards
+	return merged
 }
 
 // Queries applies variables to raw queries to transform them.
 func (dashboard *Dashboard) Queries(cfg Config) (Queries, error) {
 	transformed := make(Queries, 0, len(dashboard.RawData))
-	prefix := dashboard.Prefix
 
 	for _, raw := range dashboard.RawData {
-		if prefix != "" && !strings.Contains(string(raw), prefix) {
-			continue
-		}
-
-		if cfg.SkipRaw {
-			transformed = append(transformed, raw)
-			continue
-		}
-
-		exp, _, err := parser.ParseExpr(string(raw))
+		queries, err := dashboard.transform(raw, cfg)
 		if err != nil {
-			return nil, errors.Wrapf(err, "dashboard: parse expression %q", raw)
-		}
-
-		for _, query := range exp.Metrics() {
-			if prefix != "" {
-				if !strings.Contains(query.Metric, prefix) {
-					continue
-				}
-				if !strings.HasPrefix(query.Metric, prefix) {
-					query.Metric = query.Metric[strings.Index(query.Metric, prefix):]
-				}
-			}
-			queries := Queries{Query(query.Metric)}
-			if cfg.Unpack && strings.Contains(query.Metric, "$") {
-				queries.Convert(unpack(query.Metric, dashboard.Variables))
-			}
-			transformed = append(transformed, queries...)
+			return nil, err
 		}
+		transformed = append(transformed, queries...)
 	}
 
 	if !cfg.SkipDuplicates {
@@ -80,6 +132,80 @@ func (dashboard *Dashboard) Queries(cfg Config) (Queries, error) {
 	return transformed, nil
 }
 
+// AlertQueries applies variables to raw queries of alerts to transform them.
+func (dashboard *Dashboard) AlertQueries(cfg Config) (Queries, error) {
+	alerts := *dashboard
+	alerts.RawData = nil
+	for _, alert := range dashboard.Alerts {
+		alerts.RawData = append(alerts.RawData, alert.RawData...)
+	}
+	return alerts.Queries(cfg)
+}
+
+// Targets applies variables to raw queries of panels to transform them
+// and groups the panels by the transformed queries.
+func (dashboard *Dashboard) Targets(cfg Config) (Targets, error) {
+	targets := make(Targets, 0, len(dashboard.RawData))
+	index := make(map[Query]int, len(dashboard.RawData))
+
+	for _, panel := range dashboard.Panels {
+		for _, raw := range panel.RawData {
+			queries, err := dashboard.transform(raw, cfg)
+			if err != nil {
+				return nil, err
+			}
+			for _, query := range queries {
+				i, present := index[query]
+				if !present {
+					i = len(targets)
+					index[query] = i
+					targets = append(targets, Target{Query: query})
+				}
+				targets[i].attach(panel.Title)
+			}
+		}
+	}
+
+	if cfg.NeedSorting {
+		sort.Slice(targets, func(i, j int) bool { return targets[i].Query < targets[j].Query })
+	}
+	return targets, nil
+}
+
+func (dashboard *Dashboard) transform(raw Query, cfg Config) (Queries, error) {
+	prefix := dashboard.Prefix
+	if prefix != "" && !strings.Contains(string(raw), prefix) {
+		return nil, nil
+	}
+
+	if cfg.SkipRaw {
+		return Queries{raw}, nil
+	}
+
+	exp, _, err := parser.ParseExpr(string(raw))
+	if err != nil {
+		return nil, errors.Wrapf(err, "dashboard: parse expression %q", raw)
+	}
+
+	transformed := make(Queries, 0, 1)
+	for _, query := range exp.Metrics() {
+		if prefix != "" {
+			if !strings.Contains(query.Metric, prefix) {
+				continue
+			}
+			if !strings.HasPrefix(query.Metric, prefix) {
+				query.Metric = query.Metric[strings.Index(query.Metric, prefix):]
+			}
+		}
+		queries := Queries{Query(query.Metric)}
+		if cfg.Unpack && strings.Contains(query.Metric, "$") {
+			queries.Convert(unpack(query.Metric, dashboard.Variables))
+		}
+		transformed = append(transformed, queries...)
+	}
+	return transformed, nil
+}
+
 func unpack(metric string, variables []Variable) []string {
 	for _, variable := range variables {
 		env := "$" + variable.Name
@@ -88,11 +214,59 @@ func unpack(metric string, variables []Variable) []string {
 		}
 		// simplify logic: replace a variable by wildcard
 		// motivation: variable can use dynamic source and that fact increase the complexity of the algorithm
-		metric = strings.ReplaceAll(metric, env, "*")
+		// exception: a pinned variable is replaced by its values
+		metric = strings.ReplaceAll(metric, env, variable.value())
 	}
 	return []string{metric}
 }
 
+// A Panel represents a Grafana dashboard panel.
+type Panel struct {
+	ID       int
+	Title    string
+	RawData  []Query
+	Repeated map[string]string // values of repeat variables if the panel is a repeated copy
+}
+
+// An Annotation represents a Grafana dashboard annotation based on a query.
+type Annotation struct {
+	Name    string
+	RawData []Query
+}
+
+// An Alert represents a Grafana alert rule, a legacy panel alert or a unified one.
+type Alert struct {
+	Title   string
+	RawData []Query
+}
+
+// A Target represents a transformed query and titles of panels that use it.
+type Target struct {
+	Query  Query    `json:"query"`
+	Panels []string `json:"panels,omitempty"`
+}
+
+func (target *Target) attach(panel string) {
+	for _, title := range target.Panels {
+		if title == panel {
+			return
+		}
+	}
+	target.Panels = append(target.Panels, panel)
+}
+
+// Targets represent a slice of transformed queries with their panels.
+type Targets []Target
+
+// Queries returns transformed queries of the targets.
+func (targets Targets) Queries() Queries {
+	queries := make(Queries, 0, len(targets))
+	for _, target := range targets {
+		queries = append(queries, target.Query)
+	}
+	return queries
+}
+
 // An Option represents a possible value of the Variable.
 type Option struct {
 	Name  string
@@ -103,4 +277,101 @@ type Option struct {
 type Variable struct {
 	Name    string
 	Options []Option
+	Pinned  []string
+}
+
+// selects returns true if the value is one of pinned ones or the variable is not pinned.
+func (variable Variable) selects(value string) bool {
+	if len(variable.Pinned) == 0 {
+		return true
+	}
+	for _, pinned := range variable.Pinned {
+		if pinned == "" || pinned == "$__all" || pinned == "All" || pinned == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (variable Variable) value() string {
+	values := make([]string, 0, len(variable.Pinned))
+	for _, value := range variable.Pinned {
+		if value == "" || value == "$__all" || value == "All" {
+			return "*"
+		}
+		values = append(values, value)
+	}
+	switch len(values) {
+	case 0:
+		return "*"
+	case 1:
+		return values[0]
+	default:
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+// Pin fixes variables to the values, e.g. selected by a user,
+// to replace them by the values instead of wildcards.
+// Copies of repeated panels made for other values are removed with their queries.
+func (dashboard *Dashboard) Pin(values map[string][]string) {
+	defer dashboard.dropUnselected()
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var present bool
+		for i := range dashboard.Variables {
+			if dashboard.Variables[i].Name == name {
+				dashboard.Variables[i].Pinned, present = values[name], true
+			}
+		}
+		if !present {
+			dashboard.Variables = append(dashboard.Variables, Variable{Name: name, Pinned: values[name]})
+		}
+	}
+}
+
+func (dashboard *Dashboard) dropUnselected() {
+	variables := make(map[string]Variable, len(dashboard.Variables))
+	for _, variable := range dashboard.Variables {
+		variables[variable.Name] = variable
+	}
+
+	dropped := make(map[Query]int)
+	panels := dashboard.Panels[:0]
+	for _, panel := range dashboard.Panels {
+		selected := true
+		for name, value := range panel.Repeated {
+			if variable, present := variables[name]; present && !variable.selects(value) {
+				selected = false
+				break
+			}
+		}
+		if selected {
+			panels = append(panels, panel)
+			continue
+		}
+		for _, query := range panel.RawData {
+			dropped[query]++
+		}
+	}
+	dashboard.Panels = panels
+	if len(dropped) == 0 {
+		return
+	}
+
+	raw := dashboard.RawData[:0]
+	for _, query := range dashboard.RawData {
+		if dropped[query] > 0 {
+			dropped[query]--
+			continue
+		}
+		raw = append(raw, query)
+	}
+	dashboard.RawData = raw
 }
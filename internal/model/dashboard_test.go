@@ -145,3 +145,129 @@ func TestDashboard(t *testing.T) {
 		assert.Equal(t, 100.0, report.Total())
 	})
 }
+
+func TestDashboard_Targets(t *testing.T) {
+	dashboard := Dashboard{
+		Prefix: "apps.services.service",
+		Panels: []Panel{
+			{
+				ID:    1,
+				Title: "RPS",
+				RawData: Queries{
+					"sumSeries(apps.services.service.api.$method.count)",
+					"apps.services.service.api.total.count",
+				},
+			},
+			{
+				ID:      2,
+				Title:   "Errors",
+				RawData: Queries{"apps.services.service.api.*.count", "apps.services.other.errors"},
+			},
+		},
+		Variables: []Variable{{Name: "method"}},
+	}
+
+	targets, err := dashboard.Targets(Config{Unpack: true, NeedSorting: true})
+	require.NoError(t, err)
+	assert.Equal(t, Targets{
+		{Query: "apps.services.service.api.*.count", Panels: []string{"RPS", "Errors"}},
+		{Query: "apps.services.service.api.total.count", Panels: []string{"RPS"}},
+	}, targets)
+	assert.Equal(t, Queries{
+		"apps.services.service.api.*.count",
+		"apps.services.service.api.total.count",
+	}, targets.Queries())
+}
+
+func TestMergeDashboards(t *testing.T) {
+	a := &Dashboard{
+		UID:       "a",
+		RawData:   []Query{"app.a.*"},
+		Panels:    []Panel{{ID: 1, Title: "A", RawData: []Query{"app.a.*"}}},
+		Variables: []Variable{{Name: "env", Options: []Option{{Name: "prod", Value: "prod"}}}},
+	}
+	b := &Dashboard{
+		UID:       "b",
+		RawData:   []Query{"app.b.$env"},
+		Panels:    []Panel{{ID: 1, Title: "B", RawData: []Query{"app.b.$env"}}},
+		Variables: []Variable{{Name: "env", Options: []Option{{Name: "dev", Value: "dev"}}}},
+	}
+
+	assert.Same(t, a, MergeDashboards(a))
+
+	merged := MergeDashboards(a, b)
+	assert.Equal(t, "a,b", merged.UID)
+	assert.Equal(t, []Query{"app.a.*", "app.b.$env"}, merged.RawData)
+	assert.Len(t, merged.Panels, 2)
+	assert.Equal(t, []Variable{{Name: "env", Options: []Option{
+		{Name: "prod", Value: "prod"},
+		{Name: "dev", Value: "dev"},
+	}}}, merged.Variables)
+}
+
+func TestDashboard_AlertQueries(t *testing.T) {
+	dashboard := Dashboard{
+		Prefix:  "apps.services.service",
+		RawData: Queries{"apps.services.service.rps"},
+		Alerts: []Alert{
+			{Title: "Errors", RawData: Queries{"sumSeries(apps.services.service.errors.$code)"}},
+			{Title: "Other", RawData: Queries{"apps.services.other.errors"}},
+		},
+		Variables: []Variable{{Name: "code"}},
+	}
+	queries, err := dashboard.AlertQueries(Config{Unpack: true})
+	require.NoError(t, err)
+	assert.Equal(t, Queries{"apps.services.service.errors.*"}, queries)
+}
+
+func TestDashboard_Pin(t *testing.T) {
+	dashboard := Dashboard{
+		Prefix: "apps.services.service",
+		RawData: Queries{
+			"apps.services.service.$env.$dc.$source.rps",
+			"apps.services.service.$env.$code.errors",
+		},
+		Variables: []Variable{{Name: "env"}, {Name: "dc"}, {Name: "source"}, {Name: "code"}},
+	}
+	dashboard.Pin(map[string][]string{
+		"env":    {"prod"},
+		"dc":     {"msk", "spb"},
+		"source": {"$__all"},
+		"host":   {"unused"},
+	})
+
+	queries, err := dashboard.Queries(Config{Unpack: true})
+	require.NoError(t, err)
+	assert.Equal(t, Queries{
+		"apps.services.service.prod.{msk,spb}.*.rps",
+		"apps.services.service.prod.*.errors",
+	}, queries)
+	assert.Len(t, dashboard.Variables, 5)
+}
+
+func TestDashboard_Pin_repeated(t *testing.T) {
+	dashboard := Dashboard{
+		RawData: Queries{"apps.api.rps", "apps.grpc.rps", "apps.api.rps", "apps.total.rps"},
+		Panels: []Panel{
+			{Title: "api", RawData: []Query{"apps.api.rps"}, Repeated: map[string]string{"source": "api"}},
+			{Title: "grpc", RawData: []Query{"apps.grpc.rps"}, Repeated: map[string]string{"source": "grpc"}},
+			{Title: "API", RawData: []Query{"apps.api.rps"}},
+			{Title: "Total", RawData: []Query{"apps.total.rps"}, Repeated: map[string]string{"dc": "msk"}},
+		},
+		Variables: []Variable{{Name: "source"}, {Name: "dc"}},
+	}
+
+	all := dashboard
+	all.Panels = append([]Panel(nil), dashboard.Panels...)
+	all.RawData = append(Queries(nil), dashboard.RawData...)
+	all.Pin(map[string][]string{"source": {"All"}})
+	assert.Len(t, all.Panels, 4)
+	assert.Len(t, all.RawData, 4)
+
+	dashboard.Pin(map[string][]string{"source": {"api"}})
+	assert.Equal(t, []Query{"apps.api.rps", "apps.api.rps", "apps.total.rps"}, dashboard.RawData)
+	assert.Equal(t, []string{"api", "API", "Total"}, []string{
+		dashboard.Panels[0].Title, dashboard.Panels[1].Title, dashboard.Panels[2].Title,
+	})
+	assert.Len(t, dashboard.Panels, 3)
+}
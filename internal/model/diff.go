@@ -0,0 +1,66 @@
+package model
+
+// Kinds of metric changes between two coverage reports.
+const (
+	MetricAdded     = "added"
+	MetricRemoved   = "removed"
+	MetricCovered   = "covered"
+	MetricUncovered = "uncovered"
+	MetricHits      = "hits"
+)
+
+// A CoverageDiff contains the difference between two coverage reports.
+type CoverageDiff struct {
+	Before  float64      `json:"before"`
+	After   float64      `json:"after"`
+	Metrics []MetricDiff `json:"metrics"`
+}
+
+// Delta returns the change of the total coverage.
+func (diff CoverageDiff) Delta() float64 {
+	return diff.After - diff.Before
+}
+
+// A MetricDiff represents a change of the metric between two coverage reports.
+type MetricDiff struct {
+	Metric string `json:"name"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Change string `json:"change"`
+}
+
+// NewCoverageDiff compares two coverage reports. Metrics are listed
+// in order of the new report and then removed ones in order of the old one.
+func NewCoverageDiff(before, after CoverageReport) CoverageDiff {
+	diff := CoverageDiff{Before: before.Total(), After: after.Total(), Metrics: []MetricDiff{}}
+
+	hits := make(map[string]int, len(before.Metrics))
+	for _, hit := range before.Metrics {
+		hits[hit.Metric] = hit.Hits
+	}
+	present := make(map[string]struct{}, len(after.Metrics))
+	for _, hit := range after.Metrics {
+		present[hit.Metric] = struct{}{}
+		was, found := hits[hit.Metric]
+		change := MetricDiff{Metric: hit.Metric, Before: was, After: hit.Hits}
+		switch {
+		case !found:
+			change.Change = MetricAdded
+		case was > 0 && hit.Hits == 0:
+			change.Change = MetricUncovered
+		case was == 0 && hit.Hits > 0:
+			change.Change = MetricCovered
+		case was != hit.Hits:
+			change.Change = MetricHits
+		default:
+			continue
+		}
+		diff.Metrics = append(diff.Metrics, change)
+	}
+	for _, hit := range before.Metrics {
+		if _, found := present[hit.Metric]; !found {
+			diff.Metrics = append(diff.Metrics, MetricDiff{Metric: hit.Metric, Before: hit.Hits, Change: MetricRemoved})
+		}
+	}
+	return diff
+}
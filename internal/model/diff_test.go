@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestNewCoverageDiff(t *testing.T) {
+	var before, after CoverageReport
+	before.Add("metric.a", 1)
+	before.Add("metric.b", 0)
+	before.Add("metric.c", 2)
+	before.Add("metric.d", 1)
+	before.Add("metric.e", 1)
+	after.Add("metric.a", 0)
+	after.Add("metric.b", 3)
+	after.Add("metric.c", 1)
+	after.Add("metric.d", 1)
+	after.Add("metric.f", 0)
+
+	diff := NewCoverageDiff(before, after)
+	assert.Equal(t, 80.0, diff.Before)
+	assert.Equal(t, 60.0, diff.After)
+	assert.Equal(t, -20.0, diff.Delta())
+	assert.Equal(t, []MetricDiff{
+		{Metric: "metric.a", Before: 1, After: 0, Change: MetricUncovered},
+		{Metric: "metric.b", Before: 0, After: 3, Change: MetricCovered},
+		{Metric: "metric.c", Before: 2, After: 1, Change: MetricHits},
+		{Metric: "metric.f", Before: 0, After: 0, Change: MetricAdded},
+		{Metric: "metric.e", Before: 1, After: 0, Change: MetricRemoved},
+	}, diff.Metrics)
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-graphite/carbonapi/pkg/parser"
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+)
+
+// An Explanation describes why a metric is covered or not.
+type Explanation struct {
+	Metric   Metric       `json:"metric"`
+	Known    bool         `json:"known"`
+	Excluded Queries      `json:"excluded,omitempty"`
+	Filtered bool         `json:"filtered"`
+	Matches  []QueryMatch `json:"matches"`
+	Misses   []QueryMatch `json:"misses"`
+}
+
+// A QueryMatch describes how a query matches the metric.
+// For a near miss it contains the segment that failed to match.
+type QueryMatch struct {
+	Query    Query    `json:"query"`
+	Origin   Query    `json:"origin,omitempty"`
+	Panels   []string `json:"panels,omitempty"`
+	Segment  int      `json:"segment,omitempty"`
+	Expected string   `json:"expected,omitempty"`
+	Actual   string   `json:"actual,omitempty"`
+}
+
+// Reason returns a human-readable description of the near miss.
+func (match QueryMatch) Reason() string {
+	if match.Segment == 0 {
+		return ""
+	}
+	return "segment " + strconv.Itoa(match.Segment) + ": `" + match.Expected + "` vs `" + match.Actual + "`"
+}
+
+// NewExplainer returns new explainer of the metric coverage.
+func NewExplainer(dashboard *Dashboard, metrics Metrics, exclude Queries, filter Query) *explainer {
+	return &explainer{dashboard, metrics, exclude, filter}
+}
+
+type explainer struct {
+	dashboard *Dashboard
+	metrics   Metrics
+	exclude   Queries
+	filter    Query
+}
+
+// Explain lists the dashboard queries that match the metric and near misses.
+func (explainer *explainer) Explain(metric Metric) (Explanation, error) {
+	explanation := Explanation{Metric: metric, Matches: []QueryMatch{}, Misses: []QueryMatch{}}
+
+	for _, known := range explainer.metrics {
+		if known == metric {
+			explanation.Known = true
+			break
+		}
+	}
+	for _, query := range explainer.exclude {
+		if query.MustCompile().Match(string(metric)) {
+			explanation.Excluded = append(explanation.Excluded, query)
+		}
+	}
+	if explainer.filter != "" && !explainer.filter.MustCompile().Match(string(metric)) {
+		explanation.Filtered = true
+	}
+
+	panels := explainer.dashboard.Panels
+	if len(panels) == 0 {
+		panels = []Panel{{RawData: explainer.dashboard.RawData}}
+	} else {
+		for _, annotation := range explainer.dashboard.Annotations {
+			panels = append(panels, Panel{Title: "Annotation " + annotation.Name, RawData: annotation.RawData})
+		}
+	}
+
+	// nil position means the query is irrelevant to the metric
+	type position struct {
+		list *[]QueryMatch
+		i    int
+	}
+	seen := make(map[Query]*position)
+
+	for _, panel := range panels {
+		for _, raw := range panel.RawData {
+			exp, _, err := parser.ParseExpr(string(raw))
+			if err != nil {
+				return explanation, errors.Wrapf(err, "explain: parse expression %q", raw)
+			}
+			for _, origin := range exp.Metrics() {
+				for _, query := range unpack(origin.Metric, explainer.dashboard.Variables) {
+					if pos, present := seen[Query(query)]; present {
+						if pos != nil {
+							(*pos.list)[pos.i].attach(panel.Title)
+						}
+						continue
+					}
+
+					match := QueryMatch{Query: Query(query)}
+					if query != origin.Metric {
+						match.Origin = Query(origin.Metric)
+					}
+					match.attach(panel.Title)
+
+					var list *[]QueryMatch
+					if match.Query.MustCompile().Match(string(metric)) {
+						list = &explanation.Matches
+					} else if segment, expected, actual, near := diagnose(match.Query, metric); near {
+						match.Segment, match.Expected, match.Actual = segment, expected, actual
+						list = &explanation.Misses
+					}
+					if list == nil {
+						seen[match.Query] = nil
+						continue
+					}
+					seen[match.Query] = &position{list, len(*list)}
+					*list = append(*list, match)
+				}
+			}
+		}
+	}
+	return explanation, nil
+}
+
+func (match *QueryMatch) attach(panel string) {
+	if panel == "" {
+		return
+	}
+	for _, title := range match.Panels {
+		if title == panel {
+			return
+		}
+	}
+	match.Panels = append(match.Panels, panel)
+}
+
+// diagnose compares the query and the metric segment by segment
+// and reports the failed segment if it is the only one.
+func diagnose(query Query, metric Metric) (int, string, string, bool) {
+	qs, ms := strings.Split(string(query), "."), strings.Split(string(metric), ".")
+	min := len(qs)
+	if len(ms) < min {
+		min = len(ms)
+	}
+
+	failed := -1
+	for i := 0; i < min; i++ {
+		matcher, err := glob.Compile(qs[i])
+		if err == nil && matcher.Match(ms[i]) {
+			continue
+		}
+		if failed != -1 {
+			return 0, "", "", false
+		}
+		failed = i
+	}
+
+	if len(qs) != len(ms) {
+		if failed != -1 && failed != min-1 {
+			return 0, "", "", false
+		}
+		return min, strings.Join(qs[min-1:], "."), strings.Join(ms[min-1:], "."), true
+	}
+	if failed == -1 {
+		return 0, "", "", false
+	}
+	return failed + 1, qs[failed], ms[failed], true
+}
@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestExplainer(t *testing.T) {
+	dashboard := &Dashboard{
+		Panels: []Panel{
+			{
+				Title: "Latency",
+				RawData: Queries{
+					"aliasByNode(app.rpc.$method.percentile, 2)",
+					"app.rpc.*.percentile.95",
+				},
+			},
+			{
+				Title:   "Errors",
+				RawData: Queries{"app.rpc.*.percentile.*", "app.http.*.errors", "other.metric"},
+			},
+		},
+		Variables: []Variable{{Name: "method"}},
+	}
+	metrics := Metrics{"app.rpc.get.percentile.99"}
+
+	t.Run("near misses", func(t *testing.T) {
+		explainer := NewExplainer(dashboard, metrics, Queries{"*.max"}, "app.*")
+		explanation, err := explainer.Explain("app.rpc.get.percentile.99")
+		require.NoError(t, err)
+
+		assert.True(t, explanation.Known)
+		assert.False(t, explanation.Filtered)
+		assert.Empty(t, explanation.Excluded)
+		assert.Equal(t, []QueryMatch{
+			{Query: "app.rpc.*.percentile.*", Panels: []string{"Errors"}},
+		}, explanation.Matches)
+		assert.Equal(t, []QueryMatch{
+			{
+				Query:    "app.rpc.*.percentile",
+				Origin:   "app.rpc.$method.percentile",
+				Panels:   []string{"Latency"},
+				Segment:  4,
+				Expected: "percentile",
+				Actual:   "percentile.99",
+			},
+			{
+				Query:    "app.rpc.*.percentile.95",
+				Panels:   []string{"Latency"},
+				Segment:  5,
+				Expected: "95",
+				Actual:   "99",
+			},
+		}, explanation.Misses)
+		assert.Equal(t, "segment 4: `percentile` vs `percentile.99`", explanation.Misses[0].Reason())
+	})
+
+	t.Run("excluded and filtered", func(t *testing.T) {
+		explainer := NewExplainer(dashboard, metrics, Queries{"*.max", "app.http.*.max"}, "app.http.*")
+		explanation, err := explainer.Explain("app.rpc.get.percentile.max")
+		require.NoError(t, err)
+
+		assert.False(t, explanation.Known)
+		assert.True(t, explanation.Filtered)
+		assert.Equal(t, Queries{"*.max"}, explanation.Excluded)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		explainer := NewExplainer(&Dashboard{RawData: Queries{""}}, metrics, nil, "")
+		_, err := explainer.Explain("app.rpc.get.percentile.99")
+		assert.Error(t, err)
+	})
+}
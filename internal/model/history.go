@@ -0,0 +1,90 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// A CoverageRecord represents a single run of the coverage report.
+type CoverageRecord struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Prefix     string         `json:"prefix"`
+	Dashboards []string       `json:"dashboards"`
+	Total      float64        `json:"total"`
+	Exclude    []string       `json:"exclude,omitempty"` // queries of metrics excluded from the report
+	Filter     string         `json:"filter,omitempty"`  // a query of metrics included into the report
+	Report     CoverageReport `json:"metrics"`
+}
+
+// NewCoverageRecord returns a new record of the coverage report.
+func NewCoverageRecord(prefix string, dashboards []string, report CoverageReport) CoverageRecord {
+	return CoverageRecord{
+		Timestamp:  time.Now().UTC(),
+		Prefix:     prefix,
+		Dashboards: dashboards,
+		Total:      report.Total(),
+		Report:     report,
+	}
+}
+
+// SameFilters reports whether both records are taken with the same excluded
+// and filtered metrics, otherwise their snapshots of metrics are not comparable.
+func (record CoverageRecord) SameFilters(other CoverageRecord) bool {
+	if record.Filter != other.Filter || len(record.Exclude) != len(other.Exclude) {
+		return false
+	}
+	exclude := append([]string(nil), record.Exclude...)
+	sort.Strings(exclude)
+	another := append([]string(nil), other.Exclude...)
+	sort.Strings(another)
+	for i := range exclude {
+		if exclude[i] != another[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Metrics returns the snapshot of metrics of the record.
+func (record CoverageRecord) Metrics() Metrics {
+	metrics := make(Metrics, 0, len(record.Report.Metrics))
+	for _, hit := range record.Report.Metrics {
+		metrics = append(metrics, Metric(hit.Metric))
+	}
+	return metrics
+}
+
+// A CoverageHistory represents a chronological list of coverage records.
+type CoverageHistory []CoverageRecord
+
+// Changes returns metrics whose coverage status changed between two records.
+// Metrics that are not present in both records are ignored.
+func (history CoverageHistory) Changes(from, to int) []StatusChange {
+	if from < 0 || to < 0 || from >= len(history) || to >= len(history) {
+		return nil
+	}
+
+	return statusChanges(history[from].Report, history[to].Report)
+}
+
+func statusChanges(before, after CoverageReport) []StatusChange {
+	changes := make([]StatusChange, 0, 8)
+	for _, diff := range NewCoverageDiff(before, after).Metrics {
+		if diff.Change == MetricCovered || diff.Change == MetricUncovered {
+			changes = append(changes, StatusChange{Metric: diff.Metric, Before: diff.Before, After: diff.After})
+		}
+	}
+	return changes
+}
+
+// A StatusChange represents a metric whose coverage status changed.
+type StatusChange struct {
+	Metric string `json:"name"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// Covered returns true if the metric became covered.
+func (change StatusChange) Covered() bool {
+	return change.Before == 0 && change.After > 0
+}
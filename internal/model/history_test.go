@@ -0,0 +1,44 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestCoverageHistory_Changes(t *testing.T) {
+	var first, second CoverageReport
+	first.Add("metric.a", 1)
+	first.Add("metric.b", 0)
+	first.Add("metric.c", 2)
+	second.Add("metric.a", 0)
+	second.Add("metric.b", 3)
+	second.Add("metric.c", 1)
+	second.Add("metric.d", 0)
+
+	history := CoverageHistory{
+		NewCoverageRecord("metric", nil, first),
+		NewCoverageRecord("metric", nil, second),
+	}
+
+	changes := history.Changes(0, 1)
+	assert.Equal(t, []StatusChange{
+		{Metric: "metric.a", Before: 1, After: 0},
+		{Metric: "metric.b", Before: 0, After: 3},
+	}, changes)
+	assert.False(t, changes[0].Covered())
+	assert.True(t, changes[1].Covered())
+
+	assert.Nil(t, history.Changes(0, 2))
+}
+
+func TestCoverageRecord_SameFilters(t *testing.T) {
+	record := CoverageRecord{Exclude: []string{"*.median", "*.mean"}, Filter: "metric.rpc.*"}
+
+	assert.True(t, record.SameFilters(CoverageRecord{Exclude: []string{"*.mean", "*.median"}, Filter: "metric.rpc.*"}))
+	assert.False(t, record.SameFilters(CoverageRecord{Exclude: []string{"*.median"}, Filter: "metric.rpc.*"}))
+	assert.False(t, record.SameFilters(CoverageRecord{Exclude: []string{"*.mean", "*.p99"}, Filter: "metric.rpc.*"}))
+	assert.False(t, record.SameFilters(CoverageRecord{Exclude: []string{"*.mean", "*.median"}, Filter: "metric.*"}))
+}
@@ -0,0 +1,34 @@
+package model
+
+// FollowLinks fetches dashboards linked by the root one recursively up to the depth
+// and merges them with the root. Every dashboard is fetched once to avoid loops.
+// The fetch could return a nil dashboard without an error to skip the link.
+func FollowLinks(root *Dashboard, depth int, fetch func(uid string) (*Dashboard, error)) (*Dashboard, error) {
+	visited := map[string]struct{}{root.UID: {}}
+	dashboards := []*Dashboard{root}
+
+	level := dashboards
+	for ; depth > 0 && len(level) > 0; depth-- {
+		var next []*Dashboard
+		for _, dashboard := range level {
+			for _, uid := range dashboard.Links {
+				if _, present := visited[uid]; present {
+					continue
+				}
+				visited[uid] = struct{}{}
+
+				linked, err := fetch(uid)
+				if err != nil {
+					return nil, err
+				}
+				if linked == nil {
+					continue
+				}
+				next = append(next, linked)
+			}
+		}
+		dashboards = append(dashboards, next...)
+		level = next
+	}
+	return MergeDashboards(dashboards...), nil
+}
@@ -0,0 +1,65 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestFollowLinks(t *testing.T) {
+	dashboards := map[string]*Dashboard{
+		"root":   {UID: "root", RawData: Queries{"a"}, Links: []string{"child", "root"}},
+		"child":  {UID: "child", RawData: Queries{"b"}, Links: []string{"root", "nested"}},
+		"nested": {UID: "nested", RawData: Queries{"c"}, Links: []string{"child", "deep"}},
+		"deep":   {UID: "deep", RawData: Queries{"d"}},
+	}
+	var fetched []string
+	fetch := func(uid string) (*Dashboard, error) {
+		fetched = append(fetched, uid)
+		dashboard, present := dashboards[uid]
+		if !present {
+			return nil, errors.New("not found")
+		}
+		return dashboard, nil
+	}
+
+	t.Run("limited depth", func(t *testing.T) {
+		fetched = nil
+		dashboard, err := FollowLinks(dashboards["root"], 2, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, "root,child,nested", dashboard.UID)
+		assert.Equal(t, Queries{"a", "b", "c"}, Queries(dashboard.RawData))
+		assert.Equal(t, []string{"child", "nested"}, fetched)
+	})
+
+	t.Run("without links", func(t *testing.T) {
+		dashboard, err := FollowLinks(dashboards["root"], 0, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, dashboards["root"], dashboard)
+	})
+
+	t.Run("skipped link", func(t *testing.T) {
+		broken := &Dashboard{UID: "broken", RawData: Queries{"e"}, Links: []string{"unknown", "deep"}}
+		dashboard, err := FollowLinks(broken, 1, func(uid string) (*Dashboard, error) {
+			linked, err := fetch(uid)
+			if err != nil {
+				return nil, nil
+			}
+			return linked, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "broken,deep", dashboard.UID)
+		assert.Equal(t, Queries{"e", "d"}, Queries(dashboard.RawData))
+	})
+
+	t.Run("broken link", func(t *testing.T) {
+		broken := &Dashboard{UID: "broken", Links: []string{"unknown"}}
+		dashboard, err := FollowLinks(broken, 1, fetch)
+		assert.Error(t, err)
+		assert.Nil(t, dashboard)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strings"
 	"unsafe"
 )
 
@@ -15,6 +16,16 @@ func (metric Metric) Valid() bool {
 	return validator.MatchString(string(metric))
 }
 
+// Subtree returns the first segments of the metric after the prefix.
+func (metric Metric) Subtree(prefix string, depth int) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(string(metric), prefix), ".")
+	segments := strings.Split(name, ".")
+	if depth > 0 && depth < len(segments) {
+		segments = segments[:depth]
+	}
+	return strings.Join(segments, ".")
+}
+
 // Metrics represent a slice of metric names.
 type Metrics []Metric
 
@@ -168,3 +168,11 @@ func TestMetrics_Filter(t *testing.T) {
 		})
 	}
 }
+
+func TestMetric_Subtree(t *testing.T) {
+	metric := Metric("app.rpc.client.ok")
+	assert.Equal(t, "rpc", metric.Subtree("app", 1))
+	assert.Equal(t, "rpc.client", metric.Subtree("app", 2))
+	assert.Equal(t, "rpc.client.ok", metric.Subtree("app", 5))
+	assert.Equal(t, "rpc.client.ok", metric.Subtree("app", 0))
+}
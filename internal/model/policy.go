@@ -0,0 +1,116 @@
+package model
+
+import (
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+)
+
+// Requirements of a policy rule.
+const (
+	Required = "required"
+	Optional = "optional"
+	Ignored  = "ignored"
+)
+
+// A Rule defines a coverage requirement for metrics matched by the query.
+type Rule struct {
+	Match       Query  `json:"match" toml:"match"`
+	Requirement string `json:"requirement" toml:"requirement"`
+	MinHits     int    `json:"min_hits,omitempty" toml:"min_hits"`
+	Reason      string `json:"reason,omitempty" toml:"reason"`
+}
+
+// A Policy contains rules to evaluate a coverage report.
+// A metric is evaluated by the first matched rule,
+// metrics not matched by any rule are optional.
+type Policy struct {
+	Rules []Rule `json:"rules" toml:"rules"`
+}
+
+// Validate checks that the rules of the policy are correct.
+func (policy Policy) Validate() error {
+	if len(policy.Rules) == 0 {
+		return errors.New(`policy: there are no rules, they are defined by [[rules]] in TOML or "rules" in JSON`)
+	}
+	for i, rule := range policy.Rules {
+		if _, err := glob.Compile(string(rule.Match)); err != nil || rule.Match == "" {
+			return errors.Errorf("policy: rule #%d has invalid match %q", i+1, rule.Match)
+		}
+		switch rule.Requirement {
+		case Required, Optional, Ignored:
+		default:
+			return errors.Errorf("policy: rule #%d has invalid requirement %q, only %s, %s or %s are supported",
+				i+1, rule.Requirement, Required, Optional, Ignored)
+		}
+		if rule.MinHits < 0 {
+			return errors.Errorf("policy: rule #%d has negative min_hits", i+1)
+		}
+	}
+	return nil
+}
+
+// Evaluate applies the policy rules to the coverage report.
+func (policy Policy) Evaluate(report CoverageReport) PolicyReport {
+	result := PolicyReport{Rules: make([]RuleResult, 0, len(policy.Rules)+1)}
+	matchers := make([]Matcher, 0, len(policy.Rules))
+	for _, rule := range policy.Rules {
+		matchers = append(matchers, rule.Match.MustCompile())
+		result.Rules = append(result.Rules, RuleResult{Rule: rule, Violations: Metrics{}})
+	}
+	fallback := RuleResult{Rule: Rule{Match: "*", Requirement: Optional}, Violations: Metrics{}}
+
+	for _, hit := range report.Metrics {
+		target := &fallback
+		for i, matcher := range matchers {
+			if matcher.Match(hit.Metric) {
+				target = &result.Rules[i]
+				break
+			}
+		}
+		target.Metrics++
+
+		min := target.Rule.MinHits
+		if min == 0 {
+			min = 1
+		}
+		if hit.Hits >= min {
+			target.Covered++
+			continue
+		}
+		if target.Rule.Requirement == Required {
+			target.Violations = append(target.Violations, Metric(hit.Metric))
+		}
+	}
+
+	if fallback.Metrics > 0 {
+		result.Rules = append(result.Rules, fallback)
+	}
+	return result
+}
+
+// A PolicyReport contains results of the policy rules.
+type PolicyReport struct {
+	Rules []RuleResult `json:"rules"`
+}
+
+// Passed returns true if there are no violations of required rules.
+func (report PolicyReport) Passed() bool {
+	return report.Violations() == 0
+}
+
+// Violations returns the total number of metrics that violate required rules.
+func (report PolicyReport) Violations() int {
+	var violations int
+	for _, rule := range report.Rules {
+		violations += len(rule.Violations)
+	}
+	return violations
+}
+
+// A RuleResult contains the result of a policy rule.
+type RuleResult struct {
+	Rule       Rule    `json:"rule"`
+	Metrics    int     `json:"metrics"`
+	Covered    int     `json:"covered"`
+	Violations Metrics `json:"violations"`
+}
@@ -0,0 +1,73 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := map[string]struct {
+		policy Policy
+		assert func(assert.TestingT, error, ...interface{}) bool
+	}{
+		"valid": {
+			policy: Policy{Rules: []Rule{{Match: "*.errors", Requirement: Required, MinHits: 1}}},
+			assert: assert.NoError,
+		},
+		"without rules": {
+			policy: Policy{},
+			assert: assert.Error,
+		},
+		"empty match": {
+			policy: Policy{Rules: []Rule{{Requirement: Required}}},
+			assert: assert.Error,
+		},
+		"invalid match": {
+			policy: Policy{Rules: []Rule{{Match: "[a", Requirement: Required}}},
+			assert: assert.Error,
+		},
+		"invalid requirement": {
+			policy: Policy{Rules: []Rule{{Match: "*", Requirement: "mandatory"}}},
+			assert: assert.Error,
+		},
+		"negative min hits": {
+			policy: Policy{Rules: []Rule{{Match: "*", Requirement: Optional, MinHits: -1}}},
+			assert: assert.Error,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.assert(t, test.policy.Validate())
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	var report CoverageReport
+	report.Add("app.rpc.server.get.errors", 2)
+	report.Add("app.rpc.server.put.errors", 1)
+	report.Add("app.rpc.server.del.errors", 0)
+	report.Add("app.go.threads", 0)
+	report.Add("app.jaeger.spans", 0)
+	report.Add("app.http.requests", 1)
+
+	policy := Policy{Rules: []Rule{
+		{Match: "app.rpc.server.*.errors", Requirement: Required, MinHits: 2, Reason: "errors must be visible"},
+		{Match: "app.go.*", Requirement: Optional},
+		{Match: "app.jaeger.*", Requirement: Ignored},
+	}}
+
+	result := policy.Evaluate(report)
+	assert.False(t, result.Passed())
+	assert.Equal(t, 2, result.Violations())
+	assert.Equal(t, []RuleResult{
+		{Rule: policy.Rules[0], Metrics: 3, Covered: 1, Violations: Metrics{"app.rpc.server.put.errors", "app.rpc.server.del.errors"}},
+		{Rule: policy.Rules[1], Metrics: 1, Covered: 0, Violations: Metrics{}},
+		{Rule: policy.Rules[2], Metrics: 1, Covered: 0, Violations: Metrics{}},
+		{Rule: Rule{Match: "*", Requirement: Optional}, Metrics: 1, Covered: 1, Violations: Metrics{}},
+	}, result.Rules)
+}
@@ -10,14 +10,30 @@ type CoverageReport struct {
 
 // Add registers the metric and its hit count.
 func (report *CoverageReport) Add(name Metric, hits int) {
-	report.Metrics = append(report.Metrics, metricHit{string(name), hits})
+	report.Metrics = append(report.Metrics, metricHit{Metric: string(name), Hits: hits})
+}
+
+// HasSources returns true if the report contains hits of panels and alerts separately.
+func (report *CoverageReport) HasSources() bool {
+	for _, hit := range report.Metrics {
+		if hit.Panels != nil || hit.Alerts != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // MarshalJSON implements the Marshaler interface of the json package.
-func (report *CoverageReport) MarshalJSON() ([]byte, error) {
+// It has a value receiver to encode the report in the same way by value and by pointer.
+func (report CoverageReport) MarshalJSON() ([]byte, error) {
 	return json.Marshal(report.Metrics)
 }
 
+// UnmarshalJSON implements the Unmarshaler interface of the json package.
+func (report *CoverageReport) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &report.Metrics)
+}
+
 // Total returns coverage value of the report.
 func (report *CoverageReport) Total() float64 {
 	if len(report.Metrics) == 0 {
@@ -32,35 +48,167 @@ func (report *CoverageReport) Total() float64 {
 	return 100 * float64(hits) / float64(len(report.Metrics))
 }
 
+// Uncovered returns metrics without hits.
+func (report *CoverageReport) Uncovered() Metrics {
+	uncovered := make(Metrics, 0, len(report.Metrics))
+	for _, hit := range report.Metrics {
+		if hit.Hits == 0 {
+			uncovered = append(uncovered, Metric(hit.Metric))
+		}
+	}
+	return uncovered
+}
+
+// A DashboardCoverage represents coverage of metrics by one of several dashboards.
+type DashboardCoverage struct {
+	Dashboard string  `json:"dashboard"`
+	Covered   int     `json:"covered"`
+	Uncovered int     `json:"uncovered"`
+	Total     float64 `json:"total"`
+}
+
+// NewDashboardCoverage returns coverage of metrics by the dashboard.
+func NewDashboardCoverage(dashboard string, report CoverageReport) DashboardCoverage {
+	uncovered := len(report.Uncovered())
+	return DashboardCoverage{
+		Dashboard: dashboard,
+		Covered:   len(report.Metrics) - uncovered,
+		Uncovered: uncovered,
+		Total:     report.Total(),
+	}
+}
+
+// A CoverageSource defines queries of which dashboard parts cover metrics.
+type CoverageSource string
+
+// Supported coverage sources.
+const (
+	SourcePanels CoverageSource = "panels"
+	SourceAlerts CoverageSource = "alerts"
+	SourceBoth   CoverageSource = "both"
+)
+
+// Valid returns true if the source is supported.
+func (source CoverageSource) Valid() bool {
+	return source == SourcePanels || source == SourceAlerts || source == SourceBoth
+}
+
 // NewCoverageReporter returns new metric coverage reporter.
 func NewCoverageReporter(queries Queries) *reporter {
-	return &reporter{queries.MustMatchers()}
+	return &reporter{matchers: queries.MustMatchers()}
+}
+
+// NewSourceCoverageReporter returns new metric coverage reporter
+// that counts hits of panel and alert queries separately
+// and treats metrics as covered by the source.
+func NewSourceCoverageReporter(panels, alerts Queries, source CoverageSource) *reporter {
+	return &reporter{matchers: panels.MustMatchers(), alerts: alerts.MustMatchers(), source: source}
 }
 
 type reporter struct {
 	matchers []Matcher
+	alerts   []Matcher
+	source   CoverageSource
 }
 
 // CoverageReport builds metric coverage report.
 func (reporter *reporter) CoverageReport(metrics Metrics) CoverageReport {
 	var report CoverageReport
 
-	coverage := make(map[Metric]int, len(metrics))
-	for _, matcher := range reporter.matchers {
+	panels := countHits(reporter.matchers, metrics)
+	if reporter.source == "" {
 		for _, metric := range metrics {
-			if matcher.Match(string(metric)) {
-				coverage[metric]++
-			}
+			report.Add(metric, panels[metric])
 		}
+		return report
 	}
 
+	alerts := countHits(reporter.alerts, metrics)
 	for _, metric := range metrics {
-		report.Add(metric, coverage[metric])
+		hit := metricHit{Metric: string(metric), Panels: new(int), Alerts: new(int)}
+		*hit.Panels, *hit.Alerts = panels[metric], alerts[metric]
+		switch reporter.source {
+		case SourcePanels:
+			hit.Hits = *hit.Panels
+		case SourceAlerts:
+			hit.Hits = *hit.Alerts
+		default:
+			hit.Hits = *hit.Panels + *hit.Alerts
+		}
+		report.Metrics = append(report.Metrics, hit)
 	}
 	return report
 }
 
+func countHits(matchers []Matcher, metrics Metrics) map[Metric]int {
+	coverage := make(map[Metric]int, len(metrics))
+	for _, matcher := range matchers {
+		for _, metric := range metrics {
+			if matcher.Match(string(metric)) {
+				coverage[metric]++
+			}
+		}
+	}
+	return coverage
+}
+
 type metricHit struct {
 	Metric string `json:"name"`
 	Hits   int    `json:"hits"`
+	Panels *int   `json:"panels,omitempty"`
+	Alerts *int   `json:"alerts,omitempty"`
+}
+
+// A QueryReport contains information about how many metrics
+// are matched by each query, and which queries match nothing.
+type QueryReport struct {
+	Queries []queryHit
+}
+
+// NewQueryReport builds query report by the metrics.
+func NewQueryReport(targets Targets, metrics Metrics) QueryReport {
+	report := QueryReport{Queries: make([]queryHit, 0, len(targets))}
+	for _, target := range targets {
+		matcher, matches := target.Query.MustCompile(), 0
+		for _, metric := range metrics {
+			if matcher.Match(string(metric)) {
+				matches++
+			}
+		}
+		report.Queries = append(report.Queries, queryHit{string(target.Query), target.Panels, matches})
+	}
+	return report
+}
+
+// MarshalJSON implements the Marshaler interface of the json package.
+func (report *QueryReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(report.Queries)
+}
+
+// Dead returns the number of queries that match no metrics.
+func (report *QueryReport) Dead() int {
+	var dead int
+	for _, hit := range report.Queries {
+		if hit.Matches == 0 {
+			dead++
+		}
+	}
+	return dead
+}
+
+// OnlyDead returns the report that contains only queries that match no metrics.
+func (report *QueryReport) OnlyDead() QueryReport {
+	dead := QueryReport{Queries: make([]queryHit, 0, report.Dead())}
+	for _, hit := range report.Queries {
+		if hit.Matches == 0 {
+			dead.Queries = append(dead.Queries, hit)
+		}
+	}
+	return dead
+}
+
+type queryHit struct {
+	Query   string   `json:"query"`
+	Panels  []string `json:"panels,omitempty"`
+	Matches int      `json:"matches"`
 }
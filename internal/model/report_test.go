@@ -30,6 +30,23 @@ func TestReport(t *testing.T) {
 	assert.Equal(t, expected, buf.Bytes())
 }
 
+func TestCoverageReport_Uncovered(t *testing.T) {
+	var report CoverageReport
+	report.Add("app.a", 1)
+	report.Add("app.b", 0)
+	assert.Equal(t, Metrics{"app.b"}, report.Uncovered())
+}
+
+func TestNewDashboardCoverage(t *testing.T) {
+	var report CoverageReport
+	report.Add("metric.a", 1)
+	report.Add("metric.b", 0)
+	report.Add("metric.c", 2)
+	report.Add("metric.d", 0)
+
+	assert.Equal(t, DashboardCoverage{Dashboard: "uid", Covered: 2, Uncovered: 2, Total: 50}, NewDashboardCoverage("uid", report))
+}
+
 func TestCoverageReporter(t *testing.T) {
 	t.Run("full covered", func(t *testing.T) {
 		reporter := NewCoverageReporter(Queries{"metric.*"})
@@ -62,5 +79,56 @@ func TestCoverageReporter(t *testing.T) {
 		})
 		assert.Len(t, report.Metrics, 3)
 		assert.Equal(t, 0.0, report.Total())
+		assert.False(t, report.HasSources())
 	})
 }
+
+func TestSourceCoverageReporter(t *testing.T) {
+	metrics := Metrics{"metric.a", "metric.b", "metric.c"}
+	panels, alerts := Queries{"metric.a", "metric.{a,b}"}, Queries{"metric.b"}
+
+	tests := map[CoverageSource]struct {
+		hits  []int
+		total float64
+	}{
+		SourcePanels: {hits: []int{2, 1, 0}, total: 100 * float64(2) / float64(3)},
+		SourceAlerts: {hits: []int{0, 1, 0}, total: 100 * float64(1) / float64(3)},
+		SourceBoth:   {hits: []int{2, 2, 0}, total: 100 * float64(2) / float64(3)},
+	}
+	for source, test := range tests {
+		t.Run(string(source), func(t *testing.T) {
+			assert.True(t, source.Valid())
+
+			report := NewSourceCoverageReporter(panels, alerts, source).CoverageReport(metrics)
+			require.Len(t, report.Metrics, len(test.hits))
+			assert.True(t, report.HasSources())
+			for i, hits := range test.hits {
+				assert.Equal(t, hits, report.Metrics[i].Hits)
+			}
+			assert.Equal(t, []int{2, 1, 0}, []int{*report.Metrics[0].Panels, *report.Metrics[1].Panels, *report.Metrics[2].Panels})
+			assert.Equal(t, []int{0, 1, 0}, []int{*report.Metrics[0].Alerts, *report.Metrics[1].Alerts, *report.Metrics[2].Alerts})
+			assert.Equal(t, test.total, report.Total())
+		})
+	}
+
+	assert.False(t, CoverageSource("unknown").Valid())
+}
+
+func TestQueryReport(t *testing.T) {
+	targets := Targets{
+		{Query: "metric.*", Panels: []string{"Panel A"}},
+		{Query: "metric.b", Panels: []string{"Panel A", "Panel B"}},
+		{Query: "metric.renamed", Panels: []string{"Panel C"}},
+	}
+	report := NewQueryReport(targets, Metrics{"metric.a", "metric.b", "metric.c"})
+	require.Len(t, report.Queries, 3)
+	assert.Equal(t, 3, report.Queries[0].Matches)
+	assert.Equal(t, 1, report.Queries[1].Matches)
+	assert.Equal(t, 0, report.Queries[2].Matches)
+	assert.Equal(t, []string{"Panel C"}, report.Queries[2].Panels)
+	assert.Equal(t, 1, report.Dead())
+
+	dead := report.OnlyDead()
+	require.Len(t, dead.Queries, 1)
+	assert.Equal(t, "metric.renamed", dead.Queries[0].Query)
+}
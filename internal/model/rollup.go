@@ -0,0 +1,50 @@
+package model
+
+import "strings"
+
+// A CoverageNode contains coverage of a metric subtree.
+type CoverageNode struct {
+	Subtree  string          `json:"subtree"`
+	Total    int             `json:"total"`
+	Covered  int             `json:"covered"`
+	Children []*CoverageNode `json:"children,omitempty"`
+}
+
+// Coverage returns coverage value of the subtree.
+func (node *CoverageNode) Coverage() float64 {
+	if node.Total == 0 {
+		return 0.0
+	}
+	return 100 * float64(node.Covered) / float64(node.Total)
+}
+
+// Name returns the last segment of the subtree.
+func (node *CoverageNode) Name() string {
+	return node.Subtree[strings.LastIndex(node.Subtree, ".")+1:]
+}
+
+// Rollup groups the report by subtrees after the prefix up to the specified depth.
+// Nodes keep the order of the first metric of each subtree.
+func (report *CoverageReport) Rollup(prefix string, depth int) []*CoverageNode {
+	root := &CoverageNode{}
+	index := make(map[string]*CoverageNode)
+	for _, hit := range report.Metrics {
+		segments := strings.Split(Metric(hit.Metric).Subtree(prefix, depth), ".")
+		parent := root
+		for i := range segments {
+			subtree := strings.Join(segments[:i+1], ".")
+			node, present := index[subtree]
+			if !present {
+				node = &CoverageNode{Subtree: subtree}
+				index[subtree] = node
+				parent.Children = append(parent.Children, node)
+			}
+			node.Total++
+			if hit.Hits > 0 {
+				node.Covered++
+			}
+			parent = node
+		}
+	}
+	return root.Children
+}
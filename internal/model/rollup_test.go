@@ -0,0 +1,31 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestCoverageReport_Rollup(t *testing.T) {
+	var report CoverageReport
+	report.Add("app.rpc.client.a", 1)
+	report.Add("app.rpc.client.b", 0)
+	report.Add("app.rpc.server.a", 2)
+	report.Add("app.go.threads", 0)
+
+	nodes := report.Rollup("app", 2)
+	assert.Equal(t, []*CoverageNode{
+		{Subtree: "rpc", Total: 3, Covered: 2, Children: []*CoverageNode{
+			{Subtree: "rpc.client", Total: 2, Covered: 1},
+			{Subtree: "rpc.server", Total: 1, Covered: 1},
+		}},
+		{Subtree: "go", Total: 1, Covered: 0, Children: []*CoverageNode{
+			{Subtree: "go.threads", Total: 1, Covered: 0},
+		}},
+	}, nodes)
+	assert.Equal(t, 50.0, nodes[0].Children[0].Coverage())
+	assert.Equal(t, "client", nodes[0].Children[0].Name())
+	assert.Equal(t, 0.0, new(CoverageNode).Coverage())
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Kinds of structural changes between two JSON values.
+const (
+	ValueAdded   = "added"
+	ValueRemoved = "removed"
+	ValueChanged = "changed"
+)
+
+// A StructuralChange represents a change of a JSON value at the path.
+type StructuralChange struct {
+	Path   string      `json:"path"`
+	Change string      `json:"change"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// NewStructuralDiff compares two decoded JSON values, e.g. dashboard models.
+// Objects are compared by sorted keys, arrays by indices.
+func NewStructuralDiff(before, after interface{}) []StructuralChange {
+	changes := make([]StructuralChange, 0, 8)
+	compare("", before, after, &changes)
+	return changes
+}
+
+func compare(path string, before, after interface{}, changes *[]StructuralChange) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, is := after.(map[string]interface{}); is {
+			keys := make([]string, 0, len(a)+len(b))
+			for key := range b {
+				keys = append(keys, key)
+			}
+			for key := range a {
+				if _, present := b[key]; !present {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				next := key
+				if path != "" {
+					next = path + "." + key
+				}
+				bv, inBefore := b[key]
+				av, inAfter := a[key]
+				switch {
+				case !inBefore:
+					*changes = append(*changes, StructuralChange{Path: next, Change: ValueAdded, After: av})
+				case !inAfter:
+					*changes = append(*changes, StructuralChange{Path: next, Change: ValueRemoved, Before: bv})
+				default:
+					compare(next, bv, av, changes)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if a, is := after.([]interface{}); is {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				next := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(b):
+					*changes = append(*changes, StructuralChange{Path: next, Change: ValueAdded, After: a[i]})
+				case i >= len(a):
+					*changes = append(*changes, StructuralChange{Path: next, Change: ValueRemoved, Before: b[i]})
+				default:
+					compare(next, b[i], a[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, StructuralChange{Path: path, Change: ValueChanged, Before: before, After: after})
+	}
+}
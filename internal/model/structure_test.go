@@ -0,0 +1,32 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestNewStructuralDiff(t *testing.T) {
+	var before, after interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "A", "version": 1, "tags": ["x"],
+		"panels": [{"id": 1, "gridPos": {"y": 0}}]
+	}`), &before))
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "A", "version": 2, "editable": true,
+		"panels": [{"id": 1, "gridPos": {"y": 8}}, {"id": 2}]
+	}`), &after))
+
+	assert.Equal(t, []StructuralChange{
+		{Path: "editable", Change: ValueAdded, After: true},
+		{Path: "panels[0].gridPos.y", Change: ValueChanged, Before: 0.0, After: 8.0},
+		{Path: "panels[1]", Change: ValueAdded, After: map[string]interface{}{"id": 2.0}},
+		{Path: "tags", Change: ValueRemoved, Before: []interface{}{"x"}},
+		{Path: "version", Change: ValueChanged, Before: 1.0, After: 2.0},
+	}, NewStructuralDiff(before, after))
+	assert.Empty(t, NewStructuralDiff(before, before))
+}
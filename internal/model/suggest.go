@@ -0,0 +1,184 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// A Suggestion contains a query that covers the number of metrics.
+type Suggestion struct {
+	Query   Query `json:"query"`
+	Metrics int   `json:"metrics"`
+}
+
+// Suggest compresses the metrics into a minimal set of queries that match all of them
+// and none of the excluded ones. It merges metrics that differ in one segment by {a,b}
+// and replaces segments by * while it is safe.
+func Suggest(metrics, excluded Metrics) []Suggestion {
+	patterns := make([]pattern, 0, len(metrics))
+	registry := make(map[Metric]struct{}, len(metrics))
+	for _, metric := range metrics {
+		if _, present := registry[metric]; present {
+			continue
+		}
+		registry[metric] = struct{}{}
+		segments := strings.Split(string(metric), ".")
+		p := make(pattern, 0, len(segments))
+		for _, segment := range segments {
+			p = append(p, []string{segment})
+		}
+		patterns = append(patterns, p)
+	}
+
+	patterns = compress(patterns)
+	for _, p := range patterns {
+		p.widen(excluded)
+	}
+	patterns = compress(patterns)
+
+	suggestions := make([]Suggestion, 0, len(patterns))
+	for _, p := range patterns {
+		query := p.Query()
+		matcher, matched := query.MustCompile(), 0
+		for metric := range registry {
+			if matcher.Match(string(metric)) {
+				matched++
+			}
+		}
+		suggestions = append(suggestions, Suggestion{Query: query, Metrics: matched})
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Query < suggestions[j].Query })
+	return suggestions
+}
+
+// A pattern contains sorted values of each segment, nil means any value.
+type pattern [][]string
+
+// Query converts the pattern into a Graphite query.
+func (p pattern) Query() Query {
+	segments := make([]string, 0, len(p))
+	for _, values := range p {
+		switch len(values) {
+		case 0:
+			segments = append(segments, "*")
+		case 1:
+			segments = append(segments, values[0])
+		default:
+			segments = append(segments, "{"+strings.Join(values, ",")+"}")
+		}
+	}
+	return Query(strings.Join(segments, "."))
+}
+
+// widen replaces segments with several values by * if the pattern
+// still does not match the excluded metrics.
+func (p pattern) widen(excluded Metrics) {
+	for i := range p {
+		if len(p[i]) < 2 {
+			continue
+		}
+		values := p[i]
+		p[i] = nil
+		matcher := p.Query().MustCompile()
+		for _, metric := range excluded {
+			if matcher.Match(string(metric)) {
+				p[i] = values
+				break
+			}
+		}
+	}
+}
+
+// contains returns true if the pattern matches everything the other one matches.
+func (p pattern) contains(other pattern) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] == nil {
+			continue
+		}
+		if other[i] == nil || !subset(other[i], p[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// compress merges patterns that differ only in one segment
+// and removes patterns covered by others.
+func compress(patterns []pattern) []pattern {
+	for changed := true; changed; {
+		changed = false
+		for position := 0; ; position++ {
+			var (
+				index   = make(map[Query]int, len(patterns))
+				merged  = make([]pattern, 0, len(patterns))
+				visited bool
+			)
+			for _, p := range patterns {
+				if position >= len(p) {
+					merged = append(merged, p)
+					continue
+				}
+				visited = true
+				if p[position] == nil {
+					merged = append(merged, p)
+					continue
+				}
+				values := p[position]
+				p[position] = []string{"\x00"}
+				key := p.Query()
+				p[position] = values
+				if i, present := index[key]; present {
+					merged[i][position] = union(merged[i][position], values)
+					changed = true
+					continue
+				}
+				index[key] = len(merged)
+				merged = append(merged, p)
+			}
+			patterns = merged
+			if !visited {
+				break
+			}
+		}
+	}
+
+	compressed := make([]pattern, 0, len(patterns))
+	for i, p := range patterns {
+		redundant := false
+		for j, other := range patterns {
+			if i != j && other.contains(p) && (!p.contains(other) || j < i) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			compressed = append(compressed, p)
+		}
+	}
+	return compressed
+}
+
+func subset(values, of []string) bool {
+	for _, value := range values {
+		i := sort.SearchStrings(of, value)
+		if i == len(of) || of[i] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func union(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	out = append(out, a...)
+	for _, value := range b {
+		if !subset([]string{value}, a) {
+			out = append(out, value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
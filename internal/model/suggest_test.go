@@ -0,0 +1,43 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestSuggest(t *testing.T) {
+	t.Run("merge and widen", func(t *testing.T) {
+		metrics := Metrics{
+			"app.rpc.a.ok", "app.rpc.a.fail",
+			"app.rpc.b.ok", "app.rpc.b.fail",
+			"app.go.threads",
+		}
+		assert.Equal(t, []Suggestion{
+			{Query: "app.go.threads", Metrics: 1},
+			{Query: "app.rpc.*.*", Metrics: 4},
+		}, Suggest(metrics, nil))
+	})
+
+	t.Run("respect excluded", func(t *testing.T) {
+		metrics := Metrics{"app.rpc.a.ok", "app.rpc.a.fail", "app.rpc.b.ok", "app.rpc.b.fail"}
+		excluded := Metrics{"app.rpc.a.max"}
+		assert.Equal(t, []Suggestion{
+			{Query: "app.rpc.*.{fail,ok}", Metrics: 4},
+		}, Suggest(metrics, excluded))
+	})
+
+	t.Run("keep values", func(t *testing.T) {
+		metrics := Metrics{"app.rpc.a.ok", "app.rpc.b.ok"}
+		excluded := Metrics{"app.rpc.c.ok"}
+		assert.Equal(t, []Suggestion{
+			{Query: "app.rpc.{a,b}.ok", Metrics: 2},
+		}, Suggest(metrics, excluded))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, Suggest(nil, nil))
+	})
+}
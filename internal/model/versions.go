@@ -0,0 +1,37 @@
+package model
+
+// A VersionCoverage represents coverage by a saved version of a dashboard
+// and metrics whose coverage status changed since the previous version.
+type VersionCoverage struct {
+	DashboardVersion
+	Total   float64        `json:"total"`
+	Changes []StatusChange `json:"changes,omitempty"`
+}
+
+// Uncovered returns the number of metrics that became uncovered by the version.
+func (coverage VersionCoverage) Uncovered() int {
+	var uncovered int
+	for _, change := range coverage.Changes {
+		if !change.Covered() {
+			uncovered++
+		}
+	}
+	return uncovered
+}
+
+// NewVersionCoverage builds coverage by versions of a dashboard and their reports
+// in chronological order. The first version has no changes.
+func NewVersionCoverage(versions []DashboardVersion, reports []CoverageReport) []VersionCoverage {
+	timeline := make([]VersionCoverage, 0, len(versions))
+	for i, version := range versions {
+		if i >= len(reports) {
+			break
+		}
+		point := VersionCoverage{DashboardVersion: version, Total: reports[i].Total()}
+		if i > 0 {
+			point.Changes = statusChanges(reports[i-1], reports[i])
+		}
+		timeline = append(timeline, point)
+	}
+	return timeline
+}
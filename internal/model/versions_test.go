@@ -0,0 +1,34 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/model"
+)
+
+func TestNewVersionCoverage(t *testing.T) {
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	versions := []DashboardVersion{
+		{Version: 1, Created: now, CreatedBy: "admin"},
+		{Version: 2, Created: now.Add(time.Hour), CreatedBy: "developer", Message: "cleanup"},
+	}
+
+	var first, second CoverageReport
+	first.Add("metric.a", 1)
+	first.Add("metric.b", 1)
+	second.Add("metric.a", 1)
+	second.Add("metric.b", 0)
+
+	timeline := NewVersionCoverage(versions, []CoverageReport{first, second})
+	require.Len(t, timeline, 2)
+	assert.Equal(t, 100.0, timeline[0].Total)
+	assert.Empty(t, timeline[0].Changes)
+	assert.Equal(t, 50.0, timeline[1].Total)
+	assert.Equal(t, "developer", timeline[1].CreatedBy)
+	assert.Equal(t, []StatusChange{{Metric: "metric.b", Before: 1, After: 0}}, timeline[1].Changes)
+	assert.Equal(t, 1, timeline[1].Uncovered())
+}
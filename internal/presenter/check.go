@@ -0,0 +1,57 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintCoverageCheck prints the result of the coverage check in a specific format.
+func (printer *Printer) PrintCoverageCheck(check model.CoverageCheck) error {
+	switch printer.format {
+	case formatJSON:
+		return printCheckAsJSON(printer.output, check)
+	case formatTSV:
+		return printMetricsAsTSV(printer.output, check.Uncovered)
+	default:
+		return printCheckAsTable(printer.output, check, styles[printer.format], printer.prefix)
+	}
+}
+
+func printCheckAsJSON(output io.Writer, check model.CoverageCheck) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(check), "presenter: output result as json")
+}
+
+func printCheckAsTable(output io.Writer, check model.CoverageCheck, style *simpletable.Style, prefix string) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Newly uncovered metric of %s", prefix)},
+		},
+	}
+	for _, metric := range check.Uncovered {
+		r := []*simpletable.Cell{
+			{Text: strings.TrimPrefix(strings.TrimPrefix(string(metric), prefix), ".")},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	status := fmt.Sprintf("Total: %.2f%%, minimum: %.2f%%", check.Total, check.Minimum)
+	if check.Baseline != nil {
+		status += fmt.Sprintf(", baseline: %.2f%%", *check.Baseline)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: status},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
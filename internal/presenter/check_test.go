@@ -0,0 +1,49 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintCoverageCheck(t *testing.T) {
+	var baseline, report model.CoverageReport
+	baseline.Add("metric.a.ok", 1)
+	baseline.Add("metric.b.ok", 1)
+	report.Add("metric.a.ok", 1)
+	report.Add("metric.b.ok", 0)
+	report.Add("metric.c.ok", 0)
+	check := report.Check(50, &baseline)
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			printer.SetPrefix("metric")
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintCoverageCheck(check))
+
+			file := "testdata/check." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintCoverageCheck(check))
+	})
+}
@@ -0,0 +1,107 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintMetricChurn prints metrics added and removed between two snapshots in a specific format.
+func (printer *Printer) PrintMetricChurn(churn model.MetricChurn, depth int) error {
+	switch printer.format {
+	case formatJSON:
+		return printChurnAsJSON(printer.output, churn, depth, printer.prefix)
+	case formatTSV:
+		return printChurnAsTSV(printer.output, churn, depth, printer.prefix)
+	default:
+		return printChurnAsTable(printer.output, churn, depth, styles[printer.format], printer.prefix)
+	}
+}
+
+func printChurnAsJSON(output io.Writer, churn model.MetricChurn, depth int, prefix string) error {
+	result := struct {
+		Subtrees []model.ChurnGroup             `json:"subtrees"`
+		Stale    map[model.Metric]model.Queries `json:"stale,omitempty"`
+	}{churn.Groups(prefix, depth), churn.Stale}
+	return errors.Wrap(json.NewEncoder(output).Encode(result), "presenter: output result as json")
+}
+
+func printChurnAsTable(output io.Writer, churn model.MetricChurn, depth int, style *simpletable.Style, prefix string) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Subtree of %s", prefix)},
+			{Text: "Metric"},
+			{Text: "Change"},
+			{Text: "Stale references"},
+		},
+	}
+	var stale int
+	for _, group := range churn.Groups(prefix, depth) {
+		for _, metric := range group.Added {
+			r := []*simpletable.Cell{
+				{Text: group.Subtree},
+				{Text: strings.TrimPrefix(strings.TrimPrefix(string(metric), prefix+"."+group.Subtree), ".")},
+				{Text: "+"},
+				{Text: ""},
+			}
+			table.Body.Cells = append(table.Body.Cells, r)
+		}
+		for _, metric := range group.Removed {
+			queries := churn.Stale[metric]
+			if len(queries) > 0 {
+				stale++
+			}
+			references := make([]string, 0, len(queries))
+			for _, query := range queries {
+				references = append(references, string(query))
+			}
+			r := []*simpletable.Cell{
+				{Text: group.Subtree},
+				{Text: strings.TrimPrefix(strings.TrimPrefix(string(metric), prefix+"."+group.Subtree), ".")},
+				{Text: "-"},
+				{Text: strings.Join(references, ", ")},
+			}
+			table.Body.Cells = append(table.Body.Cells, r)
+		}
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{
+				Align: simpletable.AlignRight,
+				Span:  4,
+				Text:  fmt.Sprintf("Added: %d, removed: %d, stale: %d", len(churn.Added), len(churn.Removed), stale),
+			},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printChurnAsTSV(output io.Writer, churn model.MetricChurn, depth int, prefix string) error {
+	for _, group := range churn.Groups(prefix, depth) {
+		for _, metric := range group.Added {
+			if _, err := fmt.Fprintln(output, group.Subtree, "\t", "+", "\t", metric); err != nil {
+				return errors.Wrap(err, "presenter: output result as TSV")
+			}
+		}
+		for _, metric := range group.Removed {
+			references := make([]string, 0, len(churn.Stale[metric]))
+			for _, query := range churn.Stale[metric] {
+				references = append(references, string(query))
+			}
+			if _, err := fmt.Fprintln(output, group.Subtree, "\t", "-", "\t", metric, "\t", strings.Join(references, ",")); err != nil {
+				return errors.Wrap(err, "presenter: output result as TSV")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintMetricChurn(t *testing.T) {
+	churn := model.NewMetricChurn(
+		model.Metrics{"metric.rpc.a", "metric.rpc.b", "metric.go.pod-1.threads"},
+		model.Metrics{"metric.rpc.a", "metric.rpc.c", "metric.go.pod-2.threads"},
+	)
+	churn.Reference(model.Queries{"metric.rpc.*"})
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			printer.SetPrefix("metric")
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintMetricChurn(churn, 1))
+
+			file := "testdata/churn." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintMetricChurn(churn, 1))
+	})
+}
@@ -0,0 +1,84 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintConsumers prints dashboards, panels and queries that reference metrics in a specific format.
+func (printer *Printer) PrintConsumers(consumers model.Consumers) error {
+	switch printer.format {
+	case formatJSON:
+		return printConsumersAsJSON(printer.output, consumers)
+	case formatTSV:
+		return printConsumersAsTSV(printer.output, consumers)
+	default:
+		return printConsumersAsTable(printer.output, consumers, styles[printer.format])
+	}
+}
+
+func printConsumersAsJSON(output io.Writer, consumers model.Consumers) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(consumers), "presenter: output result as json")
+}
+
+func printConsumersAsTable(output io.Writer, consumers model.Consumers, style *simpletable.Style) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Dashboard"},
+			{Text: "Panel"},
+			{Text: "Query"},
+		},
+	}
+	for _, consumer := range consumers {
+		r := []*simpletable.Cell{
+			{Text: dashboardTitle(consumer.Dashboard)},
+			{Text: consumer.Panel},
+			{Text: string(consumer.Query)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 3, Text: fmt.Sprintf(
+				"Dashboards: %d, queries: %d", consumers.Dashboards(), len(consumers),
+			)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printConsumersAsTSV(output io.Writer, consumers model.Consumers) error {
+	for _, consumer := range consumers {
+		if _, err := fmt.Fprintln(output,
+			consumer.Dashboard.UID, "\t",
+			consumer.Dashboard.Title, "\t",
+			consumer.Panel, "\t",
+			consumer.Query, "\t",
+			consumer.Dashboard.Org,
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
+
+func dashboardTitle(dashboard model.DashboardRef) string {
+	title := fmt.Sprintf("%s (%s)", dashboard.Title, dashboard.UID)
+	if dashboard.Folder != "" {
+		title = dashboard.Folder + "/" + title
+	}
+	if dashboard.Org != "" {
+		title = dashboard.Org + ": " + title
+	}
+	return title
+}
@@ -0,0 +1,48 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintConsumers(t *testing.T) {
+	service := model.DashboardRef{UID: "DTknF4rik", Title: "Awesome Service", Folder: "Services"}
+	overview := model.DashboardRef{UID: "a8PxGq2Mk", Title: "Overview"}
+	consumers := model.Consumers{
+		{Dashboard: service, Panel: "RPS", Query: "apps.services.awesome-service.rpc.server.*.count"},
+		{Dashboard: service, Panel: "Errors", Query: "apps.services.awesome-service.rpc.server.*.error.count"},
+		{Dashboard: overview, Panel: "Services", Query: "apps.services.*.rpc.server.*.count"},
+	}
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintConsumers(consumers))
+
+			file := "testdata/consumers." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintConsumers(consumers))
+	})
+}
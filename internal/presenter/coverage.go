@@ -30,18 +30,29 @@ func printCoverageAsJSON(output io.Writer, report model.CoverageReport) error {
 }
 
 func printCoverageAsTable(output io.Writer, report model.CoverageReport, style *simpletable.Style, prefix string) error {
+	sources := report.HasSources()
+
 	table := simpletable.New()
 	table.Header = &simpletable.Header{
 		Cells: []*simpletable.Cell{
 			{Text: fmt.Sprintf("Metric of %s", prefix)},
-			{Text: "Hits"},
 		},
 	}
+	table.Header.Cells = append(table.Header.Cells, &simpletable.Cell{Text: "Hits"})
+	if sources {
+		table.Header.Cells = append(table.Header.Cells, &simpletable.Cell{Text: "Panels"}, &simpletable.Cell{Text: "Alerts"})
+	}
 	for _, metric := range report.Metrics {
 		r := []*simpletable.Cell{
 			{Text: strings.TrimPrefix(strings.TrimPrefix(metric.Metric, prefix), ".")},
 			{Align: simpletable.AlignRight, Text: strconv.Itoa(metric.Hits)},
 		}
+		if sources {
+			r = append(r,
+				&simpletable.Cell{Align: simpletable.AlignRight, Text: hits(metric.Panels)},
+				&simpletable.Cell{Align: simpletable.AlignRight, Text: hits(metric.Alerts)},
+			)
+		}
 		table.Body.Cells = append(table.Body.Cells, r)
 	}
 	table.Footer = &simpletable.Footer{
@@ -50,6 +61,9 @@ func printCoverageAsTable(output io.Writer, report model.CoverageReport, style *
 			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", report.Total())},
 		},
 	}
+	if sources {
+		table.Footer.Cells = append(table.Footer.Cells, &simpletable.Cell{}, &simpletable.Cell{})
+	}
 	table.SetStyle(style)
 
 	_, err := fmt.Fprintln(output, table.String())
@@ -57,10 +71,22 @@ func printCoverageAsTable(output io.Writer, report model.CoverageReport, style *
 }
 
 func printCoverageAsTSV(output io.Writer, report model.CoverageReport) error {
+	sources := report.HasSources()
 	for _, metric := range report.Metrics {
-		if _, err := fmt.Fprintln(output, metric.Metric, "\t", strconv.Itoa(metric.Hits)); err != nil {
+		row := []interface{}{metric.Metric, "\t", strconv.Itoa(metric.Hits)}
+		if sources {
+			row = append(row, "\t", hits(metric.Panels), "\t", hits(metric.Alerts))
+		}
+		if _, err := fmt.Fprintln(output, row...); err != nil {
 			return errors.Wrap(err, "presenter: output result as TSV")
 		}
 	}
 	return nil
 }
+
+func hits(count *int) string {
+	if count == nil {
+		return "-"
+	}
+	return strconv.Itoa(*count)
+}
@@ -184,3 +184,30 @@ func TestPrinter_PrintCoverage(t *testing.T) {
 		assert.Error(t, printer.PrintCoverageReport(coverage))
 	})
 }
+
+func TestPrinter_PrintCoverage_BySource(t *testing.T) {
+	coverage := model.NewSourceCoverageReporter(
+		model.Queries{"metric.a.*", "metric.*.ok"},
+		model.Queries{"metric.b.ok"},
+		model.SourceBoth,
+	).CoverageReport(model.Metrics{"metric.a.ok", "metric.b.ok", "metric.c.fail"})
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			printer.SetPrefix("metric")
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintCoverageReport(coverage))
+
+			file := fmt.Sprintf("testdata/coverage_by_source.%s.txt", format)
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+}
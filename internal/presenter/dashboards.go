@@ -0,0 +1,79 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintDashboardCoverage prints coverage by each of several dashboards in a specific format.
+func (printer *Printer) PrintDashboardCoverage(coverage []model.DashboardCoverage, total float64) error {
+	switch printer.format {
+	case formatJSON:
+		return printDashboardCoverageAsJSON(printer.output, coverage)
+	case formatTSV:
+		return printDashboardCoverageAsTSV(printer.output, coverage)
+	default:
+		return printDashboardCoverageAsTable(printer.output, coverage, total, styles[printer.format])
+	}
+}
+
+func printDashboardCoverageAsJSON(output io.Writer, coverage []model.DashboardCoverage) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(coverage), "presenter: output result as json")
+}
+
+func printDashboardCoverageAsTable(
+	output io.Writer,
+	coverage []model.DashboardCoverage,
+	total float64,
+	style *simpletable.Style,
+) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Dashboard"},
+			{Text: "Covered"},
+			{Text: "Uncovered"},
+			{Text: "Coverage"},
+		},
+	}
+	for _, dashboard := range coverage {
+		r := []*simpletable.Cell{
+			{Text: dashboard.Dashboard},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(dashboard.Covered)},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(dashboard.Uncovered)},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", dashboard.Total)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 3, Text: "Joint"},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", total)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printDashboardCoverageAsTSV(output io.Writer, coverage []model.DashboardCoverage) error {
+	for _, dashboard := range coverage {
+		if _, err := fmt.Fprintln(output,
+			dashboard.Dashboard, "\t",
+			strconv.Itoa(dashboard.Covered), "\t",
+			strconv.Itoa(dashboard.Uncovered), "\t",
+			fmt.Sprintf("%.2f", dashboard.Total),
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintDashboardCoverage(t *testing.T) {
+	coverage := []model.DashboardCoverage{
+		{Dashboard: "DTknF4rik", Covered: 2, Uncovered: 2, Total: 50},
+		{Dashboard: "manifests/list.yml:worker-dashboard/worker.json", Covered: 1, Uncovered: 3, Total: 25},
+	}
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintDashboardCoverage(coverage, 75))
+
+			file := "testdata/dashboards." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintDashboardCoverage(coverage, 75))
+	})
+}
@@ -0,0 +1,84 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintCoverageDiff prints the difference between two coverage reports in a specific format.
+func (printer *Printer) PrintCoverageDiff(diff model.CoverageDiff) error {
+	switch printer.format {
+	case formatJSON:
+		return printDiffAsJSON(printer.output, diff)
+	case formatTSV:
+		return printDiffAsTSV(printer.output, diff)
+	default:
+		return printDiffAsTable(printer.output, diff, styles[printer.format], printer.prefix)
+	}
+}
+
+func printDiffAsJSON(output io.Writer, diff model.CoverageDiff) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(diff), "presenter: output result as json")
+}
+
+func printDiffAsTable(output io.Writer, diff model.CoverageDiff, style *simpletable.Style, prefix string) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Metric of %s", prefix)},
+			{Text: "Before"},
+			{Text: "After"},
+			{Text: "Change"},
+		},
+	}
+	for _, metric := range diff.Metrics {
+		before, after := strconv.Itoa(metric.Before), strconv.Itoa(metric.After)
+		switch metric.Change {
+		case model.MetricAdded:
+			before = "-"
+		case model.MetricRemoved:
+			after = "-"
+		}
+		r := []*simpletable.Cell{
+			{Text: strings.TrimPrefix(strings.TrimPrefix(metric.Metric, prefix), ".")},
+			{Align: simpletable.AlignRight, Text: before},
+			{Align: simpletable.AlignRight, Text: after},
+			{Text: metric.Change},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: "Total"},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", diff.Before)},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", diff.After)},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%+.2f%%", diff.Delta())},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printDiffAsTSV(output io.Writer, diff model.CoverageDiff) error {
+	for _, metric := range diff.Metrics {
+		if _, err := fmt.Fprintln(output,
+			metric.Metric, "\t",
+			strconv.Itoa(metric.Before), "\t",
+			strconv.Itoa(metric.After), "\t",
+			metric.Change,
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintExplanation prints explanation of the metric coverage in a specific format.
+func (printer *Printer) PrintExplanation(explanation model.Explanation) error {
+	switch printer.format {
+	case formatJSON:
+		return printExplanationAsJSON(printer.output, explanation)
+	case formatTSV:
+		return printExplanationAsTSV(printer.output, explanation)
+	default:
+		return printExplanationAsTable(printer.output, explanation, styles[printer.format])
+	}
+}
+
+func printExplanationAsJSON(output io.Writer, explanation model.Explanation) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(explanation), "presenter: output result as json")
+}
+
+func printExplanationAsTable(output io.Writer, explanation model.Explanation, style *simpletable.Style) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Query of %s", explanation.Metric)},
+			{Text: "Panels"},
+			{Text: "Verdict"},
+		},
+	}
+	for _, match := range explanation.Matches {
+		table.Body.Cells = append(table.Body.Cells, explanationRow(match, "match"))
+	}
+	for _, match := range explanation.Misses {
+		table.Body.Cells = append(table.Body.Cells, explanationRow(match, match.Reason()))
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 3, Text: summary(explanation)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printExplanationAsTSV(output io.Writer, explanation model.Explanation) error {
+	for _, match := range explanation.Matches {
+		if _, err := fmt.Fprintln(output, match.Query, "\t", "match"); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	for _, match := range explanation.Misses {
+		if _, err := fmt.Fprintln(output, match.Query, "\t", match.Reason()); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
+
+func explanationRow(match model.QueryMatch, verdict string) []*simpletable.Cell {
+	if match.Origin != "" {
+		verdict += fmt.Sprintf(" (expanded from %s)", match.Origin)
+	}
+	return []*simpletable.Cell{
+		{Text: string(match.Query)},
+		{Text: strings.Join(match.Panels, ", ")},
+		{Text: strings.TrimSpace(verdict)},
+	}
+}
+
+func summary(explanation model.Explanation) string {
+	notes := make([]string, 0, 4)
+	if len(explanation.Matches) > 0 {
+		notes = append(notes, fmt.Sprintf("covered by %d queries", len(explanation.Matches)))
+	} else {
+		notes = append(notes, "not covered")
+	}
+	if !explanation.Known && len(explanation.Excluded) == 0 {
+		notes = append(notes, "not found in Graphite")
+	}
+	if len(explanation.Excluded) > 0 {
+		excluded := make([]string, 0, len(explanation.Excluded))
+		for _, query := range explanation.Excluded {
+			excluded = append(excluded, string(query))
+		}
+		notes = append(notes, "excluded by "+strings.Join(excluded, ", "))
+	}
+	if explanation.Filtered {
+		notes = append(notes, "removed by the filter")
+	}
+	return strings.Join(notes, "; ")
+}
@@ -0,0 +1,59 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintExplanation(t *testing.T) {
+	explanation := model.Explanation{
+		Metric:   "metric.rpc.get.percentile.99",
+		Known:    true,
+		Excluded: model.Queries{"*.99"},
+		Matches: []model.QueryMatch{
+			{Query: "metric.rpc.*.percentile.*", Panels: []string{"Errors"}},
+		},
+		Misses: []model.QueryMatch{
+			{
+				Query:    "metric.rpc.*.percentile",
+				Origin:   "metric.rpc.$method.percentile",
+				Panels:   []string{"Latency"},
+				Segment:  4,
+				Expected: "percentile",
+				Actual:   "percentile.99",
+			},
+		},
+	}
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintExplanation(explanation))
+
+			file := "testdata/explain." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintExplanation(explanation))
+	})
+}
@@ -0,0 +1,159 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintCoverageHistory prints coverage history in a specific format.
+func (printer *Printer) PrintCoverageHistory(history model.CoverageHistory) error {
+	switch printer.format {
+	case formatJSON:
+		return printHistoryAsJSON(printer.output, history)
+	case formatTSV:
+		return printHistoryAsTSV(printer.output, history)
+	default:
+		return printHistoryAsTable(printer.output, history, styles[printer.format])
+	}
+}
+
+// PrintStatusChanges prints metrics whose coverage status changed in a specific format.
+func (printer *Printer) PrintStatusChanges(changes []model.StatusChange) error {
+	switch printer.format {
+	case formatJSON:
+		return printChangesAsJSON(printer.output, changes)
+	case formatTSV:
+		return printChangesAsTSV(printer.output, changes)
+	default:
+		return printChangesAsTable(printer.output, changes, styles[printer.format], printer.prefix)
+	}
+}
+
+func printHistoryAsJSON(output io.Writer, history model.CoverageHistory) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(history), "presenter: output result as json")
+}
+
+func printHistoryAsTable(output io.Writer, history model.CoverageHistory, style *simpletable.Style) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "#"},
+			{Text: "Time"},
+			{Text: "Dashboards"},
+			{Text: "Metrics"},
+			{Text: "Total"},
+		},
+	}
+	totals := make([]float64, 0, len(history))
+	for i, record := range history {
+		r := []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(i + 1)},
+			{Text: record.Timestamp.Format(time.RFC3339)},
+			{Text: strings.Join(record.Dashboards, ", ")},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(len(record.Report.Metrics))},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", record.Total)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+		totals = append(totals, record.Total)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 4, Text: "Trend"},
+			{Align: simpletable.AlignRight, Text: sparkline(totals)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printHistoryAsTSV(output io.Writer, history model.CoverageHistory) error {
+	for _, record := range history {
+		if _, err := fmt.Fprintln(output,
+			record.Timestamp.Format(time.RFC3339), "\t",
+			strings.Join(record.Dashboards, ","), "\t",
+			strconv.Itoa(len(record.Report.Metrics)), "\t",
+			fmt.Sprintf("%.2f", record.Total),
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
+
+func printChangesAsJSON(output io.Writer, changes []model.StatusChange) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(changes), "presenter: output result as json")
+}
+
+func printChangesAsTable(output io.Writer, changes []model.StatusChange, style *simpletable.Style, prefix string) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Metric of %s", prefix)},
+			{Text: "Before"},
+			{Text: "After"},
+			{Text: "Status"},
+		},
+	}
+	var covered int
+	for _, change := range changes {
+		status := "uncovered"
+		if change.Covered() {
+			status = "covered"
+			covered++
+		}
+		r := []*simpletable.Cell{
+			{Text: strings.TrimPrefix(strings.TrimPrefix(change.Metric, prefix), ".")},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(change.Before)},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(change.After)},
+			{Text: status},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 4, Text: fmt.Sprintf("Covered: %d, uncovered: %d", covered, len(changes)-covered)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printChangesAsTSV(output io.Writer, changes []model.StatusChange) error {
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(output, change.Metric, "\t", strconv.Itoa(change.Before), "\t", strconv.Itoa(change.After)); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
+
+var ticks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws coverage values in percents as a tiny chart.
+func sparkline(values []float64) string {
+	line := make([]rune, 0, len(values))
+	for _, value := range values {
+		i := int(value / 100 * float64(len(ticks)-1))
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(ticks) {
+			i = len(ticks) - 1
+		}
+		line = append(line, ticks[i])
+	}
+	return string(line)
+}
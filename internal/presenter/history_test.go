@@ -0,0 +1,99 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintCoverageHistory(t *testing.T) {
+	var first, second model.CoverageReport
+	first.Add("metric.a.ok", 1)
+	first.Add("metric.b.ok", 0)
+	second.Add("metric.a.ok", 0)
+	second.Add("metric.b.ok", 2)
+	second.Add("metric.c.ok", 1)
+
+	history := model.CoverageHistory{
+		{
+			Timestamp:  time.Date(2020, time.November, 1, 12, 0, 0, 0, time.UTC),
+			Prefix:     "metric",
+			Dashboards: []string{"DTknF4rik"},
+			Total:      first.Total(),
+			Report:     first,
+		},
+		{
+			Timestamp:  time.Date(2020, time.November, 2, 12, 0, 0, 0, time.UTC),
+			Prefix:     "metric",
+			Dashboards: []string{"DTknF4rik"},
+			Total:      second.Total(),
+			Report:     second,
+		},
+	}
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			printer.SetPrefix("metric")
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintCoverageHistory(history))
+
+			file := "testdata/history." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintCoverageHistory(history))
+	})
+}
+
+func TestPrinter_PrintStatusChanges(t *testing.T) {
+	changes := []model.StatusChange{
+		{Metric: "metric.a.ok", Before: 1, After: 0},
+		{Metric: "metric.b.ok", Before: 0, After: 2},
+	}
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			printer.SetPrefix("metric")
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintStatusChanges(changes))
+
+			file := "testdata/changes." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintStatusChanges(changes))
+	})
+}
@@ -0,0 +1,86 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintPolicyReport prints the result of the coverage policy in a specific format.
+func (printer *Printer) PrintPolicyReport(report model.PolicyReport) error {
+	switch printer.format {
+	case formatJSON:
+		return printPolicyAsJSON(printer.output, report)
+	case formatTSV:
+		return printPolicyAsTSV(printer.output, report)
+	default:
+		return printPolicyAsTable(printer.output, report, styles[printer.format], printer.prefix)
+	}
+}
+
+func printPolicyAsJSON(output io.Writer, report model.PolicyReport) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(report), "presenter: output result as json")
+}
+
+func printPolicyAsTable(output io.Writer, report model.PolicyReport, style *simpletable.Style, prefix string) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Rule for %s", prefix)},
+			{Text: "Requirement"},
+			{Text: "Covered"},
+			{Text: "Violations"},
+			{Text: "Reason"},
+		},
+	}
+	for _, result := range report.Rules {
+		r := []*simpletable.Cell{
+			{Text: strings.TrimPrefix(strings.TrimPrefix(string(result.Rule.Match), prefix), ".")},
+			{Text: result.Rule.Requirement},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%d/%d", result.Covered, result.Metrics)},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%d", len(result.Violations))},
+			{Text: result.Rule.Reason},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+		for _, metric := range result.Violations {
+			r := []*simpletable.Cell{
+				{Text: strings.TrimPrefix(strings.TrimPrefix(string(metric), prefix), ".")},
+				{Text: "violation"},
+				{Text: ""},
+				{Text: ""},
+				{Text: ""},
+			}
+			table.Body.Cells = append(table.Body.Cells, r)
+		}
+	}
+	status := "Passed"
+	if !report.Passed() {
+		status = fmt.Sprintf("Failed: %d violation(s)", report.Violations())
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 5, Text: status},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printPolicyAsTSV(output io.Writer, report model.PolicyReport) error {
+	for _, result := range report.Rules {
+		for _, metric := range result.Violations {
+			if _, err := fmt.Fprintln(output, result.Rule.Match, "\t", metric); err != nil {
+				return errors.Wrap(err, "presenter: output result as TSV")
+			}
+		}
+	}
+	return nil
+}
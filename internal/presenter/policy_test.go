@@ -0,0 +1,52 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintPolicyReport(t *testing.T) {
+	var report model.CoverageReport
+	report.Add("metric.rpc.errors", 0)
+	report.Add("metric.rpc.total", 3)
+	report.Add("metric.go.goroutines", 0)
+	report.Add("metric.cache.hits", 1)
+	policy := model.Policy{Rules: []model.Rule{
+		{Match: "metric.rpc.*", Requirement: model.Required, Reason: "RPC must be observable"},
+		{Match: "metric.go.*", Requirement: model.Ignored},
+	}}
+	result := policy.Evaluate(report)
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			printer.SetPrefix("metric")
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintPolicyReport(result))
+
+			file := "testdata/policy." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintPolicyReport(result))
+	})
+}
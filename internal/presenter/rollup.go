@@ -0,0 +1,100 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintCoverageRollup prints coverage of metric subtrees in a specific format.
+func (printer *Printer) PrintCoverageRollup(nodes []*model.CoverageNode, total float64) error {
+	switch printer.format {
+	case formatJSON:
+		return printRollupAsJSON(printer.output, nodes)
+	case formatTSV:
+		return printRollupAsTSV(printer.output, nodes)
+	default:
+		return printRollupAsTable(printer.output, nodes, total, styles[printer.format], printer.prefix)
+	}
+}
+
+func printRollupAsJSON(output io.Writer, nodes []*model.CoverageNode) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(nodes), "presenter: output result as json")
+}
+
+func printRollupAsTable(
+	output io.Writer,
+	nodes []*model.CoverageNode,
+	total float64,
+	style *simpletable.Style,
+	prefix string,
+) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Subtree of %s", prefix)},
+			{Text: "Coverage"},
+			{Text: "Covered"},
+		},
+	}
+	row := func(name string, node *model.CoverageNode) {
+		r := []*simpletable.Cell{
+			{Text: name},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", node.Coverage())},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%d/%d", node.Covered, node.Total)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	var walk func(nodes []*model.CoverageNode, indent string)
+	walk = func(nodes []*model.CoverageNode, indent string) {
+		for i, node := range nodes {
+			branch, guide := "├─ ", "│  "
+			if i == len(nodes)-1 {
+				branch, guide = "└─ ", "   "
+			}
+			row(indent+branch+node.Name(), node)
+			if indent == "" && guide == "   " {
+				// leading spaces are trimmed by the table, so the blank braille pattern keeps the indent
+				guide = "\u2800  "
+			}
+			walk(node.Children, indent+guide)
+		}
+	}
+	for _, node := range nodes {
+		row(node.Subtree, node)
+		walk(node.Children, "")
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: "Total"},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", total)},
+			{Text: ""},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printRollupAsTSV(output io.Writer, nodes []*model.CoverageNode) error {
+	for _, node := range nodes {
+		if _, err := fmt.Fprintln(output,
+			node.Subtree, "\t",
+			fmt.Sprintf("%.2f", node.Coverage()), "\t",
+			node.Covered, "\t",
+			node.Total,
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+		if err := printRollupAsTSV(output, node.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
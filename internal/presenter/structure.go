@@ -0,0 +1,90 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// valueLimit is the maximum length of a value in the table.
+const valueLimit = 60
+
+// PrintStructuralDiff prints structural changes of a JSON model, e.g. a dashboard, in a specific format.
+func (printer *Printer) PrintStructuralDiff(changes []model.StructuralChange) error {
+	switch printer.format {
+	case formatJSON:
+		return printStructureAsJSON(printer.output, changes)
+	case formatTSV:
+		return printStructureAsTSV(printer.output, changes)
+	default:
+		return printStructureAsTable(printer.output, changes, styles[printer.format])
+	}
+}
+
+func printStructureAsJSON(output io.Writer, changes []model.StructuralChange) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(changes), "presenter: output result as json")
+}
+
+func printStructureAsTable(output io.Writer, changes []model.StructuralChange, style *simpletable.Style) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Path"},
+			{Text: "Change"},
+			{Text: "Before"},
+			{Text: "After"},
+		},
+	}
+	for _, change := range changes {
+		r := []*simpletable.Cell{
+			{Text: change.Path},
+			{Text: change.Change},
+			{Text: compact(change.Before, valueLimit)},
+			{Text: compact(change.After, valueLimit)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 4, Text: fmt.Sprintf("Changes: %d", len(changes))},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printStructureAsTSV(output io.Writer, changes []model.StructuralChange) error {
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(output,
+			change.Path, "\t",
+			change.Change, "\t",
+			compact(change.Before, 0), "\t",
+			compact(change.After, 0),
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
+
+// compact returns the value as compact JSON truncated to the limit, zero means no limit.
+func compact(value interface{}, limit int) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if text := []rune(string(data)); limit > 0 && len(text) > limit {
+		return string(text[:limit-1]) + "…"
+	}
+	return string(data)
+}
@@ -0,0 +1,49 @@
+package presenter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintStructuralDiff(t *testing.T) {
+	var before, after interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"panels": [{"id": 1}]}`), &before))
+	require.NoError(t, json.Unmarshal([]byte(`{"panels": [{"id": 1}, {
+		"id": 2, "type": "row", "title": "Uncovered metrics", "collapsed": false,
+		"gridPos": {"h": 1, "w": 24, "x": 0, "y": 8}
+	}], "editable": true}`), &after))
+	changes := model.NewStructuralDiff(before, after)
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintStructuralDiff(changes))
+
+			file := "testdata/structure." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintStructuralDiff(changes))
+	})
+}
@@ -0,0 +1,72 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintSuggestions prints queries that would cover uncovered metrics in a specific format.
+func (printer *Printer) PrintSuggestions(suggestions []model.Suggestion) error {
+	switch printer.format {
+	case formatJSON:
+		return printSuggestionsAsJSON(printer.output, suggestions)
+	case formatTSV:
+		return printSuggestionsAsTSV(printer.output, suggestions)
+	default:
+		return printSuggestionsAsTable(printer.output, suggestions, styles[printer.format], printer.prefix)
+	}
+}
+
+func printSuggestionsAsJSON(output io.Writer, suggestions []model.Suggestion) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(suggestions), "presenter: output result as json")
+}
+
+func printSuggestionsAsTable(
+	output io.Writer,
+	suggestions []model.Suggestion,
+	style *simpletable.Style,
+	prefix string,
+) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: fmt.Sprintf("Suggested query for %s", prefix)},
+			{Text: "Metrics"},
+		},
+	}
+	var total int
+	for _, suggestion := range suggestions {
+		total += suggestion.Metrics
+		r := []*simpletable.Cell{
+			{Text: string(suggestion.Query)},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(suggestion.Metrics)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: "Total"},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(total)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printSuggestionsAsTSV(output io.Writer, suggestions []model.Suggestion) error {
+	for _, suggestion := range suggestions {
+		if _, err := fmt.Fprintln(output, suggestion.Query, "\t", strconv.Itoa(suggestion.Metrics)); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
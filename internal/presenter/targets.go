@@ -0,0 +1,71 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintQueryReport prints query report in a specific format.
+func (printer *Printer) PrintQueryReport(report model.QueryReport) error {
+	switch printer.format {
+	case formatJSON:
+		return printQueryReportAsJSON(printer.output, report)
+	case formatTSV:
+		return printQueryReportAsTSV(printer.output, report)
+	default:
+		return printQueryReportAsTable(printer.output, report, styles[printer.format])
+	}
+}
+
+func printQueryReportAsJSON(output io.Writer, report model.QueryReport) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(&report), "presenter: output result as json")
+}
+
+func printQueryReportAsTable(output io.Writer, report model.QueryReport, style *simpletable.Style) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Query"},
+			{Text: "Matches"},
+			{Text: "Panels"},
+		},
+	}
+	for _, query := range report.Queries {
+		matches := strconv.Itoa(query.Matches)
+		if query.Matches == 0 {
+			matches = "dead"
+		}
+		r := []*simpletable.Cell{
+			{Text: query.Query},
+			{Align: simpletable.AlignRight, Text: matches},
+			{Text: strings.Join(query.Panels, ", ")},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 3, Text: fmt.Sprintf("Dead: %d of %d", report.Dead(), len(report.Queries))},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printQueryReportAsTSV(output io.Writer, report model.QueryReport) error {
+	for _, query := range report.Queries {
+		if _, err := fmt.Fprintln(output, query.Query, "\t", strconv.Itoa(query.Matches), "\t", strings.Join(query.Panels, ",")); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
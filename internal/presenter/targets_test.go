@@ -0,0 +1,48 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintQueryReport(t *testing.T) {
+	report := model.NewQueryReport(
+		model.Targets{
+			{Query: "metric.*.ok", Panels: []string{"Panel A"}},
+			{Query: "metric.renamed.ok", Panels: []string{"Panel B", "Panel C"}},
+		},
+		model.Metrics{"metric.a.ok", "metric.b.ok"},
+	)
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintQueryReport(report))
+
+			file := "testdata/targets." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintQueryReport(report))
+	})
+}
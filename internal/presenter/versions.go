@@ -0,0 +1,88 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/alexeyco/simpletable"
+	"github.com/pkg/errors"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// PrintVersionCoverage prints coverage by versions of a dashboard in a specific format.
+func (printer *Printer) PrintVersionCoverage(timeline []model.VersionCoverage) error {
+	switch printer.format {
+	case formatJSON:
+		return printVersionsAsJSON(printer.output, timeline)
+	case formatTSV:
+		return printVersionsAsTSV(printer.output, timeline)
+	default:
+		return printVersionsAsTable(printer.output, timeline, styles[printer.format])
+	}
+}
+
+func printVersionsAsJSON(output io.Writer, timeline []model.VersionCoverage) error {
+	return errors.Wrap(json.NewEncoder(output).Encode(timeline), "presenter: output result as json")
+}
+
+func printVersionsAsTable(output io.Writer, timeline []model.VersionCoverage, style *simpletable.Style) error {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Text: "Version"},
+			{Text: "Time"},
+			{Text: "Author"},
+			{Text: "Message"},
+			{Text: "Covered"},
+			{Text: "Uncovered"},
+			{Text: "Total"},
+		},
+	}
+	totals := make([]float64, 0, len(timeline))
+	for _, point := range timeline {
+		uncovered := point.Uncovered()
+		r := []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(point.Version)},
+			{Text: point.Created.Format(time.RFC3339)},
+			{Text: point.CreatedBy},
+			{Text: point.Message},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(len(point.Changes) - uncovered)},
+			{Align: simpletable.AlignRight, Text: strconv.Itoa(uncovered)},
+			{Align: simpletable.AlignRight, Text: fmt.Sprintf("%.2f%%", point.Total)},
+		}
+		table.Body.Cells = append(table.Body.Cells, r)
+		totals = append(totals, point.Total)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Span: 6, Text: "Trend"},
+			{Align: simpletable.AlignRight, Text: sparkline(totals)},
+		},
+	}
+	table.SetStyle(style)
+
+	_, err := fmt.Fprintln(output, table.String())
+	return errors.Wrap(err, "presenter: output result as table")
+}
+
+func printVersionsAsTSV(output io.Writer, timeline []model.VersionCoverage) error {
+	for _, point := range timeline {
+		uncovered := point.Uncovered()
+		if _, err := fmt.Fprintln(output,
+			strconv.Itoa(point.Version), "\t",
+			point.Created.Format(time.RFC3339), "\t",
+			point.CreatedBy, "\t",
+			point.Message, "\t",
+			strconv.Itoa(len(point.Changes)-uncovered), "\t",
+			strconv.Itoa(uncovered), "\t",
+			fmt.Sprintf("%.2f", point.Total),
+		); err != nil {
+			return errors.Wrap(err, "presenter: output result as TSV")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package presenter_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/presenter"
+)
+
+func TestPrinter_PrintVersionCoverage(t *testing.T) {
+	var first, second model.CoverageReport
+	first.Add("metric.a.ok", 1)
+	first.Add("metric.b.ok", 1)
+	second.Add("metric.a.ok", 1)
+	second.Add("metric.b.ok", 0)
+
+	timeline := model.NewVersionCoverage([]model.DashboardVersion{
+		{Version: 3, Created: time.Date(2020, time.November, 1, 12, 0, 0, 0, time.UTC), CreatedBy: "admin"},
+		{Version: 4, Created: time.Date(2020, time.November, 2, 12, 0, 0, 0, time.UTC), CreatedBy: "developer", Message: "cleanup"},
+	}, []model.CoverageReport{first, second})
+
+	for _, format := range []string{DefaultFormat, "markdown", "json", "tsv"} {
+		t.Run(format, func(t *testing.T) {
+			output := bytes.NewBuffer(nil)
+			printer := new(Printer).SetOutput(output)
+			require.NoError(t, printer.SetFormat(format))
+			require.NoError(t, printer.PrintVersionCoverage(timeline))
+
+			file := "testdata/versions." + format + ".txt"
+			if *update {
+				require.NoError(t, ioutil.WriteFile(file, output.Bytes(), 0644))
+			}
+
+			golden, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+
+	t.Run("fs unhealthy", func(t *testing.T) {
+		printer := new(Printer).SetOutput(new(unhealthy))
+		require.NoError(t, printer.SetFormat("tsv"))
+
+		assert.Error(t, printer.PrintVersionCoverage(timeline))
+	})
+}
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type manifest struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name      string            `yaml:"name"`
+		Namespace string            `yaml:"namespace"`
+		Labels    map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Data  map[string]string `yaml:"data"`
+	Items []manifest        `yaml:"items"`
+}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+// configMaps extracts JSON entries of labeled ConfigMaps from multi-document YAML,
+// e.g. rendered by Helm or produced by kubectl get -o yaml.
+func configMaps(data []byte, label string) ([]entry, error) {
+	var entries []entry
+	var collect func(manifest)
+	collect = func(m manifest) {
+		for _, item := range m.Items {
+			collect(item)
+		}
+		if m.Kind != "ConfigMap" {
+			return
+		}
+		if _, present := m.Metadata.Labels[label]; !present {
+			return
+		}
+		keys := make([]string, 0, len(m.Data))
+		for key := range m.Data {
+			if strings.HasSuffix(strings.ToLower(key), ".json") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		name := m.Metadata.Name
+		if m.Metadata.Namespace != "" {
+			name = m.Metadata.Namespace + "/" + name
+		}
+		for _, key := range keys {
+			entries = append(entries, entry{name: name + "/" + key, data: []byte(m.Data[key])})
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var m manifest
+		if err := decoder.Decode(&m); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		collect(m)
+	}
+	return entries, nil
+}
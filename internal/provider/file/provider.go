@@ -0,0 +1,161 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/provider/grafana"
+)
+
+// New returns an instance of dashboard provider based on exported JSON files.
+func New(fs afero.Fs, logger *logrus.Logger) *provider {
+	return &provider{fs: fs, logger: logger}
+}
+
+type provider struct {
+	fs     afero.Fs
+	logger *logrus.Logger
+}
+
+// Fetch reads dashboards by the patterns and extracts queries and variables from them
+// in the same way as the Grafana provider. A pattern could be a file, a glob or a directory,
+// which is walked for JSON files. Dashboards are merged into a single one.
+func (provider *provider) Fetch(ctx context.Context, patterns []string) (*model.Dashboard, error) {
+	files, err := provider.resolve(patterns, ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	dashboards := make([]*model.Dashboard, 0, len(files))
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "file: read dashboards")
+		}
+
+		provider.logger.WithField("file", file).Info("read dashboard")
+		data, err := afero.ReadFile(provider.fs, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "file: read dashboard %q", file)
+		}
+		dashboard, err := decode(data, file)
+		if err != nil {
+			return nil, err
+		}
+		dashboards = append(dashboards, dashboard)
+	}
+	return model.MergeDashboards(dashboards...), nil
+}
+
+// Manifests reads Kubernetes manifests by the patterns and extracts dashboards
+// from data of ConfigMaps with the label, like the Grafana sidecar does.
+// A pattern could be a file, a glob or a directory, which is walked for YAML files.
+// Dashboards are returned separately to report coverage by each of them.
+func (provider *provider) Manifests(ctx context.Context, patterns []string, label string) ([]*model.Dashboard, error) {
+	files, err := provider.resolve(patterns, ".yaml", ".yml")
+	if err != nil {
+		return nil, err
+	}
+
+	var dashboards []*model.Dashboard
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "file: read manifests")
+		}
+
+		provider.logger.WithField("file", file).Info("read manifest")
+		data, err := afero.ReadFile(provider.fs, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "file: read manifest %q", file)
+		}
+		entries, err := configMaps(data, label)
+		if err != nil {
+			return nil, errors.Wrapf(err, "file: decode manifest %q", file)
+		}
+		for _, entry := range entries {
+			dashboard, err := decode(entry.data, file+":"+entry.name)
+			if err != nil {
+				return nil, err
+			}
+			dashboards = append(dashboards, dashboard)
+		}
+	}
+	if len(dashboards) == 0 {
+		return nil, errors.Errorf("file: no dashboards found in ConfigMaps labeled %q", label)
+	}
+	return dashboards, nil
+}
+
+func decode(data []byte, source string) (*model.Dashboard, error) {
+	dashboard, err := grafana.Decode(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "file: decode dashboard %q", source)
+	}
+	if dashboard.UID == "" {
+		dashboard.UID = source
+	}
+	return dashboard, nil
+}
+
+func (provider *provider) resolve(patterns []string, extensions ...string) ([]string, error) {
+	registry := make(map[string]struct{})
+	files := make([]string, 0, len(patterns))
+	add := func(file string) {
+		if _, present := registry[file]; !present {
+			registry[file] = struct{}{}
+			files = append(files, file)
+		}
+	}
+	match := func(path string) bool {
+		for _, extension := range extensions {
+			if strings.EqualFold(filepath.Ext(path), extension) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, pattern := range patterns {
+		matches, err := afero.Glob(provider.fs, pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "file: invalid pattern %q", pattern)
+		}
+		if len(matches) == 0 {
+			return nil, errors.Errorf("file: nothing found by %q", pattern)
+		}
+		sort.Strings(matches)
+		for _, path := range matches {
+			info, err := provider.fs.Stat(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "file: stat %q", path)
+			}
+			if !info.IsDir() {
+				add(path)
+				continue
+			}
+			err = afero.Walk(provider.fs, path, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if !info.IsDir() && match(path) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "file: walk %q", path)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("file: no files found by %s", strings.Join(patterns, ", "))
+	}
+	return files, nil
+}
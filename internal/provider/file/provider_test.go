@@ -0,0 +1,99 @@
+package file_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/provider/file"
+)
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	provider := New(afero.NewOsFs(), logger)
+
+	t.Run("file", func(t *testing.T) {
+		dashboard, err := provider.Fetch(ctx, []string{"testdata/dashboards/nested/worker.json"})
+		require.NoError(t, err)
+		assert.Equal(t, "Xyz", dashboard.UID)
+		assert.Equal(t, []model.Query{"apps.services.worker.queue.size"}, dashboard.RawData)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dashboard, err := provider.Fetch(ctx, []string{"testdata/dashboards"})
+		require.NoError(t, err)
+		assert.Equal(t, "Xyz,DTknF4rik", dashboard.UID)
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		dashboard, err := provider.Fetch(ctx, []string{"testdata/dashboards/*.json", "testdata/dashboards/service.json"})
+		require.NoError(t, err)
+		assert.Equal(t, "DTknF4rik", dashboard.UID)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		dashboard, err := provider.Fetch(ctx, []string{"testdata/unknown/*.json"})
+		assert.Error(t, err)
+		assert.Nil(t, dashboard)
+	})
+
+	t.Run("invalid dashboard", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "invalid.json", []byte("invalid"), 0644))
+
+		dashboard, err := New(fs, logger).Fetch(ctx, []string{"invalid.json"})
+		assert.Error(t, err)
+		assert.Nil(t, dashboard)
+	})
+}
+
+func TestProvider_Manifests(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	provider := New(afero.NewOsFs(), logger)
+
+	t.Run("helm output", func(t *testing.T) {
+		dashboards, err := provider.Manifests(ctx, []string{"testdata/manifests/helm.yaml"}, "grafana_dashboard")
+		require.NoError(t, err)
+		require.Len(t, dashboards, 1)
+		assert.Equal(t, "DTknF4rik", dashboards[0].UID)
+		assert.Equal(t, []model.Query{"apps.services.awesome-service.rpc.server.*.success.ok.count"}, dashboards[0].RawData)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dashboards, err := provider.Manifests(ctx, []string{"testdata/manifests"}, "grafana_dashboard")
+		require.NoError(t, err)
+		require.Len(t, dashboards, 2)
+		assert.Equal(t, "DTknF4rik", dashboards[0].UID)
+		assert.Equal(t, "testdata/manifests/list.yml:worker-dashboard/worker.json", dashboards[1].UID)
+		assert.Len(t, dashboards[1].Panels, 1)
+	})
+
+	t.Run("unknown label", func(t *testing.T) {
+		dashboards, err := provider.Manifests(ctx, []string{"testdata/manifests"}, "unknown")
+		assert.Error(t, err)
+		assert.Nil(t, dashboards)
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "invalid.yaml", []byte("kind: [ConfigMap"), 0644))
+
+		dashboards, err := New(fs, logger).Manifests(ctx, []string{"invalid.yaml"}, "grafana_dashboard")
+		assert.Error(t, err)
+		assert.Nil(t, dashboards)
+	})
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/spf13/afero"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+//go:generate mockgen -source $GOFILE -destination mocks_test.go -package ${GOPACKAGE}_test
+
+// A Grafana defines Grafana provider interface.
+type Grafana interface {
+	Fetch(context.Context, string) (*model.Dashboard, error)
+	Version(context.Context, string) (int, error)
+	LibraryVersion(context.Context, string) (int, error)
+}
+
+// Proxies for mocking.
+type (
+	File interface{ afero.File }
+	FS   interface{ afero.Fs }
+)
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"go.octolab.org/safe"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+// Decorate wraps Grafana provider by cache layer.
+// The scope separates dashboards of different Grafana instances and organizations
+// because their unique identifiers are unique only within an organization.
+func Decorate(provider Grafana, scope string, fs afero.Fs, logger *logrus.Logger) Grafana {
+	return &decorator{provider, scope, fs, logger}
+}
+
+// Filename returns cache file name.
+func Filename(scope, uid string) string {
+	if scope != "" {
+		uid = unsafeChars.ReplaceAllString(scope, "_") + "." + uid
+	}
+	return filepath.Join(os.TempDir(), uid) + ".grafaman.dashboard.json"
+}
+
+var unsafeChars = regexp.MustCompile(`[^\w.-]+`)
+
+// format is a version of the cached data layout;
+// it must be increased on every change of the model.Dashboard or the way it is filled
+// between releases.
+const format = 1
+
+type decorator struct {
+	provider Grafana
+	scope    string
+	fs       afero.Fs
+	logger   *logrus.Logger
+}
+
+// Fetch tries to load a dashboard from cache first and revalidates it
+// by its version or fallback to a decorated provider and store its success response.
+func (decorator *decorator) Fetch(ctx context.Context, uid string) (*model.Dashboard, error) {
+	filename := Filename(decorator.scope, uid)
+	logger := decorator.logger.WithFields(logrus.Fields{"component": "cache", "file": filename})
+	file, err := decorator.fs.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		logger.WithError(err).Error("prepare storage")
+		return nil, errors.Wrap(err, "cache: prepare storage")
+	}
+	defer safe.Close(file, func(err error) { logger.WithError(err).Warning("flush data") })
+
+	var data struct {
+		Dashboard *model.Dashboard `json:"dashboard,omitempty"`
+		Format    int              `json:"format,omitempty"`
+	}
+	if err := json.NewDecoder(file).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		logger.WithError(err).Error("decode data")
+		return nil, errors.Wrap(err, "cache: decode data")
+	}
+
+	if data.Dashboard != nil && data.Format == format {
+		version, err := decorator.provider.Version(ctx, uid)
+		if err == nil && version == data.Dashboard.Version && decorator.fresh(ctx, data.Dashboard, logger) {
+			logger.WithField("version", version).Info("fetch data from cache")
+			return data.Dashboard, nil
+		}
+		if err != nil {
+			logger.WithError(err).Warning("revalidate data")
+		}
+	}
+
+	data.Dashboard, err = decorator.provider.Fetch(ctx, uid)
+	if err != nil {
+		logger.WithError(err).Error("fetch data")
+		return nil, errors.Wrap(err, "cache: fetch data")
+	}
+	data.Format = format
+
+	if err := file.Truncate(0); err != nil {
+		logger.WithError(err).Error("truncate data")
+		return nil, errors.Wrap(err, "cache: truncate data")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		logger.WithError(err).Error("prepare to write")
+		return nil, errors.Wrap(err, "cache: prepare to write")
+	}
+	if err := json.NewEncoder(file).Encode(data); err != nil {
+		logger.WithError(err).Error("store data")
+		return nil, errors.Wrap(err, "cache: store data")
+	}
+
+	logger.WithField("version", data.Dashboard.Version).Info("store data to cache until the next version")
+	return data.Dashboard, nil
+}
+
+// fresh checks that library panels of the dashboard are not changed,
+// their changes do not increase the version of the dashboard.
+func (decorator *decorator) fresh(ctx context.Context, dashboard *model.Dashboard, logger *logrus.Entry) bool {
+	for uid, version := range dashboard.Libraries {
+		actual, err := decorator.provider.LibraryVersion(ctx, uid)
+		if err != nil {
+			logger.WithError(err).WithField("library", uid).Warning("revalidate library panel")
+			return false
+		}
+		if actual != version {
+			return false
+		}
+	}
+	return true
+}
+
+// Version returns the latest version of a dashboard from a decorated provider.
+func (decorator *decorator) Version(ctx context.Context, uid string) (int, error) {
+	return decorator.provider.Version(ctx, uid)
+}
+
+// LibraryVersion returns the current version of a library panel from a decorated provider.
+func (decorator *decorator) LibraryVersion(ctx context.Context, uid string) (int, error) {
+	return decorator.provider.LibraryVersion(ctx, uid)
+}
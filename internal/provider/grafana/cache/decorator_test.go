@@ -0,0 +1,213 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	. "github.com/kamilsk/grafaman/internal/provider/grafana/cache"
+)
+
+func TestDecorate(t *testing.T) {
+	ctx, uid := context.Background(), "test"
+	dashboard := &model.Dashboard{
+		UID:     uid,
+		Version: 2,
+		RawData: []model.Query{"metric.a", "metric.b", "metric.c"},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	t.Run("fetch data from cache", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Version(ctx, uid).
+			Return(dashboard.Version, nil)
+
+		fs := afero.NewMemMapFs()
+		file, err := fs.Create(Filename("", uid))
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(file).Encode(map[string]interface{}{"dashboard": dashboard, "format": 1}))
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, dashboard, obtained)
+	})
+
+	t.Run("revalidate outdated data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		outdated := *dashboard
+		outdated.Version, outdated.RawData = 1, []model.Query{"metric.a"}
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Version(ctx, uid).
+			Return(dashboard.Version, nil)
+		provider.EXPECT().
+			Fetch(ctx, uid).
+			Return(dashboard, nil)
+
+		fs := afero.NewMemMapFs()
+		file, err := fs.Create(Filename("", uid))
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(file).Encode(map[string]interface{}{"dashboard": outdated, "format": 1}))
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, dashboard, obtained)
+	})
+
+	t.Run("revalidate changed library panels", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		outdated := *dashboard
+		outdated.RawData, outdated.Libraries = []model.Query{"metric.a"}, map[string]int{"rps": 1}
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Version(ctx, uid).
+			Return(dashboard.Version, nil)
+		provider.EXPECT().
+			LibraryVersion(ctx, "rps").
+			Return(2, nil)
+		provider.EXPECT().
+			Fetch(ctx, uid).
+			Return(dashboard, nil)
+
+		fs := afero.NewMemMapFs()
+		file, err := fs.Create(Filename("", uid))
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(file).Encode(map[string]interface{}{"dashboard": outdated, "format": 1}))
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, dashboard, obtained)
+	})
+
+	t.Run("refetch data of outdated format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Fetch(ctx, uid).
+			Return(dashboard, nil)
+
+		fs := afero.NewMemMapFs()
+		file, err := fs.Create(Filename("", uid))
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(file).Encode(map[string]interface{}{"dashboard": dashboard}))
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, dashboard, obtained)
+	})
+
+	t.Run("fail to revalidate data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Version(ctx, uid).
+			Return(0, errors.New("service unavailable"))
+		provider.EXPECT().
+			Fetch(ctx, uid).
+			Return(dashboard, nil)
+
+		fs := afero.NewMemMapFs()
+		file, err := fs.Create(Filename("", uid))
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(file).Encode(map[string]interface{}{"dashboard": dashboard, "format": 1}))
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, dashboard, obtained)
+	})
+
+	t.Run("store data to cache", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Fetch(ctx, uid).
+			Return(dashboard, nil)
+
+		fs := afero.NewMemMapFs()
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, dashboard, obtained)
+
+		exists, err := afero.Exists(fs, Filename("", uid))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("fail to fetch data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		provider := NewMockGrafana(ctrl)
+		provider.EXPECT().
+			Fetch(ctx, uid).
+			Return(nil, errors.New("service unavailable"))
+
+		decorator := Decorate(provider, "", afero.NewMemMapFs(), logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		require.Error(t, err)
+		assert.EqualError(t, err, "cache: fetch data: service unavailable")
+		assert.Nil(t, obtained)
+	})
+
+	t.Run("fail to prepare storage", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		provider := NewMockGrafana(ctrl)
+		fs := NewMockFS(ctrl)
+		fs.EXPECT().
+			OpenFile(Filename("", uid), os.O_RDWR|os.O_CREATE, os.FileMode(0644)).
+			Return(nil, errors.New("fs unhealthy"))
+
+		decorator := Decorate(provider, "", fs, logger)
+		obtained, err := decorator.Fetch(ctx, uid)
+		require.Error(t, err)
+		assert.EqualError(t, err, "cache: prepare storage: fs unhealthy")
+		assert.Nil(t, obtained)
+	})
+}
+
+func TestFilename(t *testing.T) {
+	filename := Filename("", "test")
+	assert.Equal(t, "test.grafaman.dashboard.json", filepath.Base(filename))
+	assert.Equal(t, ".json", filepath.Ext(filename))
+
+	filename = Filename("grafana.api:3000/org/2", "test")
+	assert.Equal(t, "grafana.api_3000_org_2.test.grafaman.dashboard.json", filepath.Base(filename))
+}
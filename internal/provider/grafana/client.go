@@ -0,0 +1,23 @@
+package grafana
+
+import "net/http"
+
+// WithOrg returns the client that sends requests on behalf of the organization.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/auth/#x-grafana-org-id-header.
+func WithOrg(client Client, org string) Client {
+	if org == "" {
+		return client
+	}
+	return &orgClient{client, org}
+}
+
+type orgClient struct {
+	client Client
+	org    string
+}
+
+// Do sets the organization header and sends the request.
+func (client *orgClient) Do(request *http.Request) (*http.Response, error) {
+	request.Header.Set("X-Grafana-Org-Id", client.org)
+	return client.client.Do(request)
+}
@@ -1,18 +1,158 @@
 package grafana
 
-import "github.com/kamilsk/grafaman/internal/model"
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/kamilsk/grafaman/internal/model"
+)
+
+const limitParam = "limit"
+
+// legacySchemaVersion is the first schema version with panels instead of rows.
+const legacySchemaVersion = 16
 
 type dashboard struct {
-	Panels     []panel    `json:"panels,omitempty"`
-	Templating templating `json:"templating,omitempty"`
+	UID           string      `json:"uid,omitempty"`
+	Version       int         `json:"version,omitempty"`
+	SchemaVersion int         `json:"schemaVersion,omitempty"`
+	Panels        []panel     `json:"panels,omitempty"`
+	Rows          []row       `json:"rows,omitempty"`
+	Templating    templating  `json:"templating,omitempty"`
+	Annotations   annotations `json:"annotations,omitempty"`
+	Links         []link      `json:"links,omitempty"`
+}
+
+// layout returns panels of the dashboard, legacy rows are converted to row panels.
+func (dashboard dashboard) layout() []panel {
+	if dashboard.SchemaVersion >= legacySchemaVersion || len(dashboard.Rows) == 0 {
+		return dashboard.Panels
+	}
+
+	panels := make([]panel, 0, len(dashboard.Rows)+len(dashboard.Panels))
+	for _, row := range dashboard.Rows {
+		panels = append(panels, panel{Title: row.Title, Type: "row", Repeat: row.Repeat, Panels: row.Panels})
+	}
+	return append(panels, dashboard.Panels...)
+}
+
+type meta struct {
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+type version struct {
+	Version   int       `json:"version,omitempty"`
+	Created   time.Time `json:"created,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+type org struct {
+	ID    int    `json:"id,omitempty"`
+	OrgID int    `json:"orgId,omitempty"` // the current user organizations
+	Name  string `json:"name,omitempty"`
+}
+
+type searchHit struct {
+	UID         string `json:"uid,omitempty"`
+	Title       string `json:"title,omitempty"`
+	URL         string `json:"url,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+}
+
+// versions is a response of the dashboard versions API.
+// Grafana returns a plain list or, since v10, an object with the list.
+type versions struct {
+	Versions []version `json:"versions,omitempty"`
+}
+
+func (list *versions) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &list.Versions); err == nil {
+		return nil
+	}
+	type plain versions
+	return json.Unmarshal(data, (*plain)(list))
+}
+
+type annotations struct {
+	List []annotation `json:"list,omitempty"`
+}
+
+type annotation struct {
+	Name       string      `json:"name,omitempty"`
+	Datasource interface{} `json:"datasource,omitempty"` // name or reference with type
+	Target     string      `json:"target,omitempty"`
+}
+
+type link struct {
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type row struct {
+	Title  string  `json:"title,omitempty"`
+	Repeat string  `json:"repeat,omitempty"`
+	Panels []panel `json:"panels,omitempty"`
 }
 
 type panel struct {
-	ID      int      `json:"id,omitempty"`
-	Title   string   `json:"title,omitempty"`
-	Type    string   `json:"type,omitempty"`
-	Panels  []panel  `json:"panels,omitempty"`
-	Targets []target `json:"targets,omitempty"`
+	ID           int           `json:"id,omitempty"`
+	Title        string        `json:"title,omitempty"`
+	Type         string        `json:"type,omitempty"`
+	Repeat       string        `json:"repeat,omitempty"`
+	Panels       []panel       `json:"panels,omitempty"`
+	Targets      []target      `json:"targets,omitempty"`
+	Links        []link        `json:"links,omitempty"`
+	LibraryPanel *libraryPanel `json:"libraryPanel,omitempty"`
+	Alert        *alert        `json:"alert,omitempty"`
+
+	repeated map[string]string // values of repeat variables the copy is made for
+}
+
+// alert is a legacy panel alert, its conditions refer to targets of the panel.
+type alert struct {
+	Name       string      `json:"name,omitempty"`
+	Conditions []condition `json:"conditions,omitempty"`
+}
+
+type condition struct {
+	Query conditionQuery `json:"query,omitempty"`
+}
+
+type conditionQuery struct {
+	Params []string `json:"params,omitempty"`
+}
+
+// alertRule is a unified alerting rule.
+type alertRule struct {
+	UID   string      `json:"uid,omitempty"`
+	Title string      `json:"title,omitempty"`
+	Data  []ruleQuery `json:"data,omitempty"`
+}
+
+type ruleQuery struct {
+	RefID string `json:"refId,omitempty"`
+	Model struct {
+		Target     string `json:"target,omitempty"`
+		Datasource struct {
+			Type string `json:"type,omitempty"`
+		} `json:"datasource,omitempty"`
+	} `json:"model,omitempty"`
+}
+
+type libraryPanel struct {
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type libraryElement struct {
+	UID     string `json:"uid,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Model   panel  `json:"model,omitempty"`
 }
 
 type templating struct {
@@ -20,6 +160,7 @@ type templating struct {
 }
 
 type target struct {
+	RefID string `json:"refId,omitempty"`
 	Query string `json:"target,omitempty"`
 }
 
@@ -39,6 +180,27 @@ type currentOption struct {
 	Value interface{} `json:"value,omitempty"` // string or []string
 }
 
+func convertDashboard(uid string, in dashboard, meta meta, host string) *model.Dashboard {
+	variables := fetchVariables(in)
+	panels := expandPanels(in.layout(), variables)
+	annotations := convertAnnotations(in.Annotations.List)
+	out := &model.Dashboard{
+		UID:         uid,
+		Version:     in.Version,
+		Updated:     meta.Updated,
+		RawData:     convertTargets(fetchTargets(panels)),
+		Panels:      convertPanels(panels),
+		Annotations: annotations,
+		Alerts:      convertAlerts(panels),
+		Links:       convertLinks(in.Links, panels, host),
+		Variables:   convertVariables(variables),
+	}
+	for _, annotation := range annotations {
+		out.RawData = append(out.RawData, annotation.RawData...)
+	}
+	return out
+}
+
 func convertTargets(in []target) []model.Query {
 	out := make([]model.Query, 0, len(in))
 
@@ -65,6 +227,127 @@ func fetchTargets(panels []panel) []target {
 	return targets
 }
 
+func convertPanels(panels []panel) []model.Panel {
+	out := make([]model.Panel, 0, len(panels))
+	for _, panel := range panels {
+		if count := len(panel.Targets); count > 0 {
+			out = append(out, model.Panel{
+				ID:       panel.ID,
+				Title:    panel.Title,
+				RawData:  convertTargets(panel.Targets),
+				Repeated: panel.repeated,
+			})
+			continue
+		}
+		out = append(out, convertPanels(panel.Panels)...)
+	}
+	return out
+}
+
+// convertAnnotations returns annotations based on Graphite series.
+func convertAnnotations(in []annotation) []model.Annotation {
+	out := make([]model.Annotation, 0, len(in))
+	for _, annotation := range in {
+		if reference, is := annotation.Datasource.(map[string]interface{}); is {
+			if kind, _ := reference["type"].(string); kind != "graphite" {
+				continue
+			}
+		}
+		if annotation.Target == "" {
+			continue
+		}
+		out = append(out, model.Annotation{
+			Name:    annotation.Name,
+			RawData: []model.Query{model.Query(annotation.Target)},
+		})
+	}
+	return out
+}
+
+// dashboardURL matches a dashboard unique identifier in its URL, e.g. /d/DTknF4rik/awesome-service.
+var dashboardURL = regexp.MustCompile(`(?:^|/)d/([^/?#$]+)(?:[/?#]|$)`)
+
+// convertLinks returns unique identifiers of dashboards linked by the dashboard and its panels.
+// Absolute links to other hosts than the given one are ignored, an empty host allows any of them.
+func convertLinks(links []link, panels []panel, host string) []string {
+	var collect func([]panel)
+	collect = func(panels []panel) {
+		for _, panel := range panels {
+			links = append(links, panel.Links...)
+			collect(panel.Panels)
+		}
+	}
+	collect(panels)
+
+	registry := make(map[string]struct{}, len(links))
+	out := make([]string, 0, len(links))
+	for _, link := range links {
+		match := dashboardURL.FindStringSubmatch(link.URL)
+		if match == nil {
+			continue
+		}
+		if host != "" {
+			if u, err := url.Parse(link.URL); err != nil || u.Host != "" && u.Host != host {
+				continue
+			}
+		}
+		if _, present := registry[match[1]]; present {
+			continue
+		}
+		registry[match[1]] = struct{}{}
+		out = append(out, match[1])
+	}
+	return out
+}
+
+func convertAlerts(panels []panel) []model.Alert {
+	var out []model.Alert
+	for _, panel := range panels {
+		out = append(out, convertAlerts(panel.Panels)...)
+		if panel.Alert == nil {
+			continue
+		}
+
+		refs := make(map[string]struct{}, len(panel.Alert.Conditions))
+		for _, condition := range panel.Alert.Conditions {
+			if len(condition.Query.Params) > 0 {
+				refs[condition.Query.Params[0]] = struct{}{}
+			}
+		}
+		targets := make([]target, 0, len(refs))
+		for _, target := range panel.Targets {
+			if _, present := refs[target.RefID]; present || len(refs) == 0 {
+				targets = append(targets, target)
+			}
+		}
+		title := panel.Alert.Name
+		if title == "" {
+			title = panel.Title
+		}
+		out = append(out, model.Alert{Title: title, RawData: convertTargets(targets)})
+	}
+	return out
+}
+
+func convertRules(rules []alertRule) []model.Alert {
+	out := make([]model.Alert, 0, len(rules))
+	for _, rule := range rules {
+		queries := make([]model.Query, 0, len(rule.Data))
+		for _, data := range rule.Data {
+			if kind := data.Model.Datasource.Type; kind != "" && kind != "graphite" {
+				continue
+			}
+			if data.Model.Target != "" {
+				queries = append(queries, model.Query(data.Model.Target))
+			}
+		}
+		if len(queries) > 0 {
+			out = append(out, model.Alert{Title: rule.Title, RawData: queries})
+		}
+	}
+	return out
+}
+
 func convertVariables(in []variable) []model.Variable {
 	out := make([]model.Variable, 0, len(in))
 
@@ -37,6 +37,99 @@ func TestConvertTargets(t *testing.T) {
 	}
 }
 
+func TestConvertPanels(t *testing.T) {
+	panels := []panel{
+		{
+			ID:      1,
+			Title:   "Panel A",
+			Targets: []target{{Query: "metric.a"}, {Query: ""}},
+		},
+		{
+			ID:    2,
+			Title: "Row",
+			Type:  "row",
+			Panels: []panel{
+				{ID: 3, Title: "Panel B", Targets: []target{{Query: "metric.b"}}},
+			},
+		},
+	}
+	expected := []model.Panel{
+		{ID: 1, Title: "Panel A", RawData: []model.Query{"metric.a"}},
+		{ID: 3, Title: "Panel B", RawData: []model.Query{"metric.b"}},
+	}
+	assert.Equal(t, expected, convertPanels(panels))
+}
+
+func TestConvertAlerts(t *testing.T) {
+	panels := []panel{
+		{
+			Title: "RPS",
+			Targets: []target{
+				{RefID: "A", Query: "metric.rps"},
+				{RefID: "B", Query: "metric.errors"},
+			},
+			Alert: &alert{
+				Name:       "Too many errors",
+				Conditions: []condition{{Query: conditionQuery{Params: []string{"B", "5m", "now"}}}},
+			},
+		},
+		{
+			Title: "Row",
+			Type:  "row",
+			Panels: []panel{
+				{Title: "Latency", Targets: []target{{RefID: "A", Query: "metric.latency"}}, Alert: &alert{}},
+				{Title: "Quiet", Targets: []target{{RefID: "A", Query: "metric.quiet"}}},
+			},
+		},
+	}
+	expected := []model.Alert{
+		{Title: "Too many errors", RawData: []model.Query{"metric.errors"}},
+		{Title: "Latency", RawData: []model.Query{"metric.latency"}},
+	}
+	assert.Equal(t, expected, convertAlerts(panels))
+}
+
+func TestConvertAnnotations(t *testing.T) {
+	annotations := []annotation{
+		{Name: "Deploys", Datasource: "graphite", Target: "apps.services.awesome-service.deploys"},
+		{Name: "Restarts", Datasource: map[string]interface{}{"type": "graphite"}, Target: "apps.services.*.restarts"},
+		{Name: "Incidents", Datasource: map[string]interface{}{"type": "loki"}, Target: "{app=\"awesome-service\"}"},
+		{Name: "Annotations & Alerts", Datasource: "-- Grafana --"},
+	}
+	expected := []model.Annotation{
+		{Name: "Deploys", RawData: []model.Query{"apps.services.awesome-service.deploys"}},
+		{Name: "Restarts", RawData: []model.Query{"apps.services.*.restarts"}},
+	}
+	assert.Equal(t, expected, convertAnnotations(annotations))
+
+	in := dashboard{Panels: []panel{{ID: 1, Title: "RPS", Targets: []target{{Query: "apps.services.awesome-service.rps"}}}}}
+	in.Annotations.List = annotations
+	out := convertDashboard("uid", in, meta{}, "")
+	assert.Equal(t, expected, out.Annotations)
+	assert.Equal(t, []model.Panel{{ID: 1, Title: "RPS", RawData: []model.Query{"apps.services.awesome-service.rps"}}}, out.Panels)
+	assert.Equal(t, []model.Query{
+		"apps.services.awesome-service.rps",
+		"apps.services.awesome-service.deploys",
+		"apps.services.*.restarts",
+	}, out.RawData)
+}
+
+func TestConvertLinks(t *testing.T) {
+	links := []link{
+		{Type: "link", URL: "/d/DTknF4rik/awesome-service?var-env=prod"},
+		{Type: "link", URL: "https://grafana.example.com/d/Xyz"},
+		{Type: "link", URL: "https://grafana.other.com/d/Abc"},
+		{Type: "link", URL: "https://example.com/docs"},
+		{Type: "dashboards"},
+	}
+	panels := []panel{
+		{Links: []link{{URL: "/d/DTknF4rik"}}},
+		{Type: "row", Panels: []panel{{Links: []link{{URL: "d/nested#panel"}, {URL: "/d/${uid}"}}}}},
+	}
+	assert.Equal(t, []string{"DTknF4rik", "Xyz", "nested"}, convertLinks(links, panels, "grafana.example.com"))
+	assert.Equal(t, []string{"DTknF4rik", "Xyz", "Abc", "nested"}, convertLinks(links, panels, ""))
+}
+
 func TestDumpStubs(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	if *update {
@@ -125,3 +218,73 @@ func TestDumpStubs(t *testing.T) {
 		require.NoError(t, file.Close())
 	})
 }
+
+func TestDashboard_Layout(t *testing.T) {
+	rows := []row{{Title: "Row", Panels: []panel{{ID: 1, Targets: []target{{Query: "metric.a"}}}}}}
+
+	t.Run("legacy", func(t *testing.T) {
+		legacy := dashboard{SchemaVersion: 14, Rows: rows}
+		assert.Equal(t, []panel{{Title: "Row", Type: "row", Panels: rows[0].Panels}}, legacy.layout())
+	})
+
+	t.Run("actual", func(t *testing.T) {
+		actual := dashboard{SchemaVersion: 22, Rows: rows, Panels: []panel{{ID: 2}}}
+		assert.Equal(t, []panel{{ID: 2}}, actual.layout())
+	})
+}
+
+func TestExpandPanels(t *testing.T) {
+	variables := []variable{
+		{Name: "source", Options: []option{{Value: allValue}, {Value: "api"}, {Value: "grpc"}}},
+		{Name: "code", Options: []option{{Value: "200"}, {Value: "500"}}},
+		{Name: "empty"},
+	}
+	panels := []panel{
+		{
+			Title:  "$source",
+			Type:   "row",
+			Repeat: "source",
+			Panels: []panel{
+				{ID: 1, Title: "RPS", Targets: []target{{Query: "apps.[[source]].rps"}}},
+				{ID: 2, Title: "Code $code", Repeat: "code", Targets: []target{{Query: "apps.${source}.code.${code:raw}"}}},
+			},
+		},
+		{ID: 3, Title: "Errors", Repeat: "empty", Targets: []target{{Query: "apps.$empty.errors"}}},
+	}
+
+	expanded := expandPanels(panels, variables)
+	assert.Equal(t, []model.Query{
+		"apps.api.rps",
+		"apps.api.code.200",
+		"apps.api.code.500",
+		"apps.grpc.rps",
+		"apps.grpc.code.200",
+		"apps.grpc.code.500",
+		"apps.$empty.errors",
+	}, convertTargets(fetchTargets(expanded)))
+	assert.Equal(t, []model.Panel{
+		{ID: 1, Title: "RPS", RawData: []model.Query{"apps.api.rps"}, Repeated: map[string]string{"source": "api"}},
+		{ID: 2, Title: "Code 200", RawData: []model.Query{"apps.api.code.200"},
+			Repeated: map[string]string{"source": "api", "code": "200"}},
+		{ID: 2, Title: "Code 500", RawData: []model.Query{"apps.api.code.500"},
+			Repeated: map[string]string{"source": "api", "code": "500"}},
+		{ID: 1, Title: "RPS", RawData: []model.Query{"apps.grpc.rps"}, Repeated: map[string]string{"source": "grpc"}},
+		{ID: 2, Title: "Code 200", RawData: []model.Query{"apps.grpc.code.200"},
+			Repeated: map[string]string{"source": "grpc", "code": "200"}},
+		{ID: 2, Title: "Code 500", RawData: []model.Query{"apps.grpc.code.500"},
+			Repeated: map[string]string{"source": "grpc", "code": "500"}},
+		{ID: 3, Title: "Errors", RawData: []model.Query{"apps.$empty.errors"}},
+	}, convertPanels(expanded))
+
+	t.Run("pinned", func(t *testing.T) {
+		dashboard := convertDashboard("uid", dashboard{Panels: panels, Templating: templating{List: variables}}, meta{}, "")
+		dashboard.Pin(map[string][]string{"source": {"api"}})
+		assert.Equal(t, []model.Query{
+			"apps.api.rps",
+			"apps.api.code.200",
+			"apps.api.code.500",
+			"apps.$empty.errors",
+		}, dashboard.RawData)
+		assert.Len(t, dashboard.Panels, 4)
+	})
+}
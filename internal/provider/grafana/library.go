@@ -0,0 +1,115 @@
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// resolveLibraryPanels replaces references to library panels by their models
+// and returns versions of the used library panels by their unique identifiers.
+func (provider *provider) resolveLibraryPanels(ctx context.Context, dashboard *dashboard) (map[string]int, error) {
+	versions := make(map[string]int)
+	for i := range dashboard.Rows {
+		if err := provider.resolvePanels(ctx, dashboard.Rows[i].Panels, versions); err != nil {
+			return nil, err
+		}
+	}
+	if err := provider.resolvePanels(ctx, dashboard.Panels, versions); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return versions, nil
+}
+
+func (provider *provider) resolvePanels(ctx context.Context, panels []panel, versions map[string]int) error {
+	for i := range panels {
+		if err := provider.resolvePanels(ctx, panels[i].Panels, versions); err != nil {
+			return err
+		}
+		if panels[i].LibraryPanel == nil || panels[i].LibraryPanel.UID == "" {
+			continue
+		}
+		element, err := provider.libraryElement(ctx, panels[i].LibraryPanel.UID)
+		if err != nil {
+			return err
+		}
+		versions[element.UID] = element.Version
+		panels[i] = mergeLibraryPanel(panels[i], element)
+	}
+	return nil
+}
+
+// LibraryVersion returns the current version of a library panel.
+func (provider *provider) LibraryVersion(ctx context.Context, uid string) (int, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	element, err := provider.libraryElement(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	return element.Version, nil
+}
+
+// libraryElement returns a library panel by its unique identifier.
+// Elements are cached by the provider because they are shared between dashboards.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/library_element/#get-library-element-by-uid.
+func (provider *provider) libraryElement(ctx context.Context, uid string) (libraryElement, error) {
+	provider.mu.Lock()
+	element, present := provider.library[uid]
+	provider.mu.Unlock()
+	if present {
+		return element, nil
+	}
+
+	const source = "/api/library-elements/"
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, source, uid)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return libraryElement{}, errors.Wrap(err, "grafana: create library panel request")
+	}
+
+	var payload struct {
+		Result libraryElement `json:"result,omitempty"`
+	}
+	if err := provider.fetch(request, &payload); err != nil {
+		return libraryElement{}, errors.WithMessagef(err, "grafana: fetch library panel %q", uid)
+	}
+	if payload.Result.UID == "" {
+		payload.Result.UID = uid
+	}
+
+	provider.mu.Lock()
+	provider.library[uid] = payload.Result
+	provider.mu.Unlock()
+	return payload.Result, nil
+}
+
+// mergeLibraryPanel fills the panel by targets of the library one
+// and names it by the library panel to attribute its queries.
+func mergeLibraryPanel(in panel, element libraryElement) panel {
+	out := in
+	out.LibraryPanel = nil
+	out.Title = element.Name
+	if out.Title == "" {
+		out.Title = in.LibraryPanel.Name
+	}
+	if out.Title == "" {
+		out.Title = element.Model.Title
+	}
+	if out.Type == "" {
+		out.Type = element.Model.Type
+	}
+	if out.Repeat == "" {
+		out.Repeat = element.Model.Repeat
+	}
+	out.Targets = element.Model.Targets
+	return out
+}
@@ -1,11 +1,15 @@
 package grafana
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/kamilsk/retry/v5"
@@ -30,6 +34,7 @@ func New(endpoint string, client Client, logger *logrus.Logger, listener Progres
 		endpoint: *u,
 		logger:   logger,
 		listener: listener,
+		library:  make(map[string]libraryElement),
 	}, nil
 }
 
@@ -38,6 +43,9 @@ type provider struct {
 	endpoint url.URL
 	logger   *logrus.Logger
 	listener ProgressListener
+
+	mu      sync.Mutex
+	library map[string]libraryElement
 }
 
 // Fetch takes a dashboard JSON model and extracts queries and variables from it.
@@ -55,11 +63,341 @@ func (provider *provider) Fetch(ctx context.Context, uid string) (*model.Dashboa
 		return nil, errors.Wrap(err, "grafana: create dashboard base request")
 	}
 
+	var payload struct {
+		Dashboard dashboard `json:"dashboard,omitempty"`
+		Meta      meta      `json:"meta,omitempty"`
+	}
+	if err := provider.fetch(request, &payload); err != nil {
+		return nil, err
+	}
+	libraries, err := provider.resolveLibraryPanels(ctx, &payload.Dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	out := convertDashboard(uid, payload.Dashboard, payload.Meta, provider.endpoint.Host)
+	out.Libraries = libraries
+	return out, nil
+}
+
+// Decode extracts queries and variables from a dashboard JSON model in the same way as Fetch.
+// The model could be bare, e.g. exported from Grafana, or wrapped by the API response.
+func Decode(data []byte) (*model.Dashboard, error) {
+	var payload struct {
+		Dashboard *dashboard `json:"dashboard,omitempty"`
+		Meta      meta       `json:"meta,omitempty"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.Wrap(err, "grafana: decode dashboard model")
+	}
+	if payload.Dashboard != nil {
+		return convertDashboard(payload.Dashboard.UID, *payload.Dashboard, payload.Meta, ""), nil
+	}
+
+	var bare dashboard
+	if err := json.Unmarshal(data, &bare); err != nil {
+		return nil, errors.Wrap(err, "grafana: decode dashboard model")
+	}
+	return convertDashboard(bare.UID, bare, meta{}, ""), nil
+}
+
+// Alerts returns unified alerting rules based on Graphite queries.
+// Grafana without unified alerting has no rules API, so it has no unified rules,
+// its legacy alerts are the part of dashboard panels.
+// Documentation: https://grafana.com/docs/grafana/latest/developers/http_api/alerting_provisioning/#route-get-alert-rules.
+func (provider *provider) Alerts(ctx context.Context) ([]model.Alert, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const source = "/api/v1/provisioning/alert-rules"
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, source)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "grafana: create alert rules request")
+	}
+
+	var payload []alertRule
+	if err := provider.fetch(request, &payload); err != nil {
+		var status statusError
+		if errors.As(err, &status) && (status.code == http.StatusNotFound || status.code == http.StatusForbidden) {
+			provider.logger.WithError(err).Warning("unified alerting is not available, only legacy alerts are used")
+			return nil, nil
+		}
+		return nil, err
+	}
+	return convertRules(payload), nil
+}
+
+// Orgs returns organizations the credentials can access.
+// All organizations are available only for server admins,
+// so it falls back to organizations of the current user.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/org/#search-all-organizations.
+func (provider *provider) Orgs(ctx context.Context) ([]model.Org, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	var (
+		payload []org
+		err     error
+	)
+	for _, source := range []string{"/api/orgs", "/api/user/orgs"} {
+		u := provider.endpoint
+		u.Path = path.Join(u.Path, source)
+		request, rerr := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if rerr != nil {
+			return nil, errors.Wrap(rerr, "grafana: create organizations request")
+		}
+		if err = provider.fetch(request, &payload); err == nil {
+			break
+		}
+		provider.logger.WithError(err).WithField("source", source).Warning("fetch organizations")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]model.Org, 0, len(payload))
+	for _, org := range payload {
+		id := org.ID
+		if id == 0 {
+			id = org.OrgID
+		}
+		out = append(out, model.Org{ID: id, Name: org.Name})
+	}
+	return out, nil
+}
+
+// Search returns dashboards of the organization, all or only with the unique identifier.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/folder_dashboard_search/.
+func (provider *provider) Search(ctx context.Context, uid string) ([]model.DashboardRef, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const (
+		source = "/api/search"
+		limit  = 5000
+	)
+
+	var out []model.DashboardRef
+	for page := 1; ; page++ {
+		u := provider.endpoint
+		u.Path = path.Join(u.Path, source)
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "grafana: create search request")
+		}
+		q := request.URL.Query()
+		q.Add("type", "dash-db")
+		q.Add(limitParam, strconv.Itoa(limit))
+		q.Add("page", strconv.Itoa(page))
+		if uid != "" {
+			q.Add("dashboardUIDs", uid)
+		}
+		request.URL.RawQuery = q.Encode()
+
+		var payload []searchHit
+		if err := provider.fetch(request, &payload); err != nil {
+			return nil, err
+		}
+		for _, hit := range payload {
+			out = append(out, model.DashboardRef{UID: hit.UID, Title: hit.Title, URL: hit.URL, Folder: hit.FolderTitle})
+		}
+		if len(payload) < limit {
+			return out, nil
+		}
+	}
+}
+
+// Version returns the latest saved version of a dashboard.
+// It is much cheaper than Fetch and is used to revalidate a cached dashboard.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/dashboard_versions/#get-all-dashboard-versions.
+func (provider *provider) Version(ctx context.Context, uid string) (int, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const source = "/api/dashboards/uid/%s/versions"
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, fmt.Sprintf(source, uid))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "grafana: create dashboard versions base request")
+	}
+	q := request.URL.Query()
+	q.Add(limitParam, "1")
+	request.URL.RawQuery = q.Encode()
+
+	var payload versions
+	if err := provider.fetch(request, &payload); err != nil {
+		return 0, err
+	}
+	if len(payload.Versions) == 0 {
+		return 0, errors.Errorf("grafana: dashboard %q has no versions", uid)
+	}
+	return payload.Versions[0].Version, nil
+}
+
+// Versions returns saved versions of a dashboard created since the time, the latest first.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/dashboard_versions/#get-all-dashboard-versions.
+func (provider *provider) Versions(ctx context.Context, uid string, since time.Time) ([]model.DashboardVersion, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const (
+		source = "/api/dashboards/uid/%s/versions"
+		limit  = 1000
+	)
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, fmt.Sprintf(source, uid))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "grafana: create dashboard versions base request")
+	}
+	q := request.URL.Query()
+	q.Add(limitParam, strconv.Itoa(limit))
+	request.URL.RawQuery = q.Encode()
+
+	var payload versions
+	if err := provider.fetch(request, &payload); err != nil {
+		return nil, err
+	}
+	out := make([]model.DashboardVersion, 0, len(payload.Versions))
+	for _, version := range payload.Versions {
+		if version.Created.Before(since) {
+			continue
+		}
+		out = append(out, model.DashboardVersion{
+			Version:   version.Version,
+			Created:   version.Created,
+			CreatedBy: version.CreatedBy,
+			Message:   version.Message,
+		})
+	}
+	return out, nil
+}
+
+// FetchVersion takes a saved version of a dashboard JSON model and extracts queries and variables from it.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/dashboard_versions/#get-dashboard-version.
+func (provider *provider) FetchVersion(ctx context.Context, uid string, number int) (*model.Dashboard, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const source = "/api/dashboards/uid/%s/versions/%d"
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, fmt.Sprintf(source, uid, number))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "grafana: create dashboard version request")
+	}
+
+	var payload struct {
+		version
+		Data dashboard `json:"data,omitempty"`
+	}
+	if err := provider.fetch(request, &payload); err != nil {
+		return nil, err
+	}
+	libraries, err := provider.resolveLibraryPanels(ctx, &payload.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	payload.Data.Version = payload.Version
+	out := convertDashboard(uid, payload.Data, meta{Updated: payload.Created}, provider.endpoint.Host)
+	out.Libraries = libraries
+	return out, nil
+}
+
+// Source returns the raw JSON model of a dashboard with its folder.
+// Unlike Fetch it keeps the whole model to modify and save it back.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/dashboard/#get-dashboard-by-uid.
+func (provider *provider) Source(ctx context.Context, uid string) (*model.DashboardSource, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const source = "/api/dashboards/uid/"
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, source, uid)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "grafana: create dashboard source request")
+	}
+
+	var payload struct {
+		Dashboard map[string]interface{} `json:"dashboard,omitempty"`
+		Meta      struct {
+			FolderID int `json:"folderId,omitempty"`
+		} `json:"meta,omitempty"`
+	}
+	if err := provider.fetch(request, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Dashboard == nil {
+		return nil, errors.Errorf("grafana: dashboard %q has no model", uid)
+	}
+	return &model.DashboardSource{Model: payload.Dashboard, FolderID: payload.Meta.FolderID}, nil
+}
+
+// Save stores the dashboard model with the commit message and returns its new version.
+// The model must contain the version it is based on, otherwise Grafana rejects
+// the request to avoid overwriting changes made by someone else.
+// Documentation: https://grafana.com/docs/grafana/latest/http_api/dashboard/#create--update-dashboard.
+func (provider *provider) Save(ctx context.Context, source *model.DashboardSource, message string) (int, error) {
+	provider.listener.OnStepQueued()
+	defer provider.listener.OnStepDone()
+
+	const destination = "/api/dashboards/db"
+
+	body, err := json.Marshal(struct {
+		Dashboard map[string]interface{} `json:"dashboard"`
+		FolderID  int                    `json:"folderId"`
+		Message   string                 `json:"message,omitempty"`
+		Overwrite bool                   `json:"overwrite"`
+	}{source.Model, source.FolderID, message, false})
+	if err != nil {
+		return 0, errors.Wrap(err, "grafana: encode dashboard save request")
+	}
+
+	u := provider.endpoint
+	u.Path = path.Join(u.Path, destination)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "grafana: create dashboard save request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	// the request is not idempotent: a retry after a lost response
+	// of the successful save is rejected because of the changed version
+	var payload version
+	if err := provider.send(request, &payload, 1); err != nil {
+		return 0, err
+	}
+	return payload.Version, nil
+}
+
+// fetch sends the idempotent request with retries on network errors and decodes the response.
+func (provider *provider) fetch(request *http.Request, payload interface{}) error {
+	return provider.send(request, payload, 3)
+}
+
+func (provider *provider) send(request *http.Request, payload interface{}, attempts uint) error {
 	var response *http.Response
+
 	what := func(ctx context.Context) error {
 		var err error
 		logger := provider.logger.WithField("url", request.URL.String())
 		logger.Info("start to fetch data")
+		if request.GetBody != nil {
+			// the body is consumed by the previous attempt
+			if request.Body, err = request.GetBody(); err != nil {
+				return errors.Wrap(err, "grafana: rewind request body")
+			}
+		}
 		response, err = provider.client.Do(request) // nolint:bodyclose
 		if err != nil {
 			logger.WithError(err).Error("fail fetch data")
@@ -69,7 +407,7 @@ func (provider *provider) Fetch(ctx context.Context, uid string) (*model.Dashboa
 		return nil
 	}
 	how := retry.How{
-		strategy.Limit(3),
+		strategy.Limit(attempts),
 		strategy.Backoff(
 			backoff.Linear(50 * time.Millisecond),
 		),
@@ -78,20 +416,29 @@ func (provider *provider) Fetch(ctx context.Context, uid string) (*model.Dashboa
 		),
 	}
 	if err := retry.Do(request.Context(), what, how...); err != nil {
-		return nil, err
+		return err
 	}
 	defer safe.Close(response.Body, unsafe.Ignore)
 
-	var payload struct {
-		Dashboard dashboard `json:"dashboard,omitempty"`
+	if response.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Message string `json:"message,omitempty"`
+		}
+		unsafe.Ignore(json.NewDecoder(response.Body).Decode(&failure))
+		return errors.WithStack(statusError{response.StatusCode, failure.Message})
 	}
-	if err := json.NewDecoder(response.Body).Decode(&payload); err != nil {
-		return nil, errors.Wrap(err, "grafana: decode dashboard fetch response")
+	if err := json.NewDecoder(response.Body).Decode(payload); err != nil {
+		return errors.Wrap(err, "grafana: decode dashboard fetch response")
 	}
+	return nil
+}
 
-	result := model.Dashboard{
-		RawData:   convertTargets(fetchTargets(payload.Dashboard.Panels)),
-		Variables: convertVariables(fetchVariables(payload.Dashboard)),
-	}
-	return &result, nil
+// statusError represents an unsuccessful response of Grafana API.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (err statusError) Error() string {
+	return fmt.Sprintf("grafana: unexpected response status %d: %s", err.code, err.message)
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -17,6 +18,7 @@ import (
 	"go.octolab.org/safe"
 	"go.octolab.org/unsafe"
 
+	"github.com/kamilsk/grafaman/internal/model"
 	. "github.com/kamilsk/grafaman/internal/provider/grafana"
 )
 
@@ -47,6 +49,141 @@ func TestProvider(t *testing.T) {
 		assert.NotNil(t, dashboard)
 	})
 
+	t.Run("success fetch with library panels", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "test/api/dashboards/uid/library", request.URL.Path)
+				return response("testdata/library.json")
+			})
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "test/api/library-elements/rps", request.URL.Path)
+				return response("testdata/library-element.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(2)
+		progress.EXPECT().OnStepQueued().Times(2)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		dashboard, err := provider.Fetch(ctx, "library")
+		require.NoError(t, err)
+		assert.Equal(t, []model.Panel{
+			{ID: 1, Title: "Panel A", RawData: []model.Query{"apps.services.awesome-service.rpc.client.*.count"}},
+			{ID: 2, Title: "RPS", RawData: []model.Query{"apps.services.awesome-service.rpc.server.*.count"}},
+			{ID: 4, Title: "RPS", RawData: []model.Query{"apps.services.awesome-service.rpc.server.*.count"}},
+		}, dashboard.Panels)
+		assert.Equal(t, map[string]int{"rps": 3}, dashboard.Libraries)
+
+		version, err := provider.LibraryVersion(ctx, "rps")
+		require.NoError(t, err)
+		assert.Equal(t, 3, version)
+	})
+
+	t.Run("success fetch alerts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "test/api/v1/provisioning/alert-rules", request.URL.Path)
+				return response("testdata/alerts.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		alerts, err := provider.Alerts(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []model.Alert{{
+			Title:   "Too many errors",
+			RawData: []model.Query{"sumSeries(apps.services.awesome-service.rpc.server.*.error.count)"},
+		}}, alerts)
+	})
+
+	t.Run("fetch alerts without unified alerting", func(t *testing.T) {
+		for _, stub := range []string{"testdata/not-found.json", "testdata/forbidden.json"} {
+			ctrl := gomock.NewController(t)
+
+			client := NewMockClient(ctrl)
+			client.EXPECT().
+				Do(gomock.Any()).
+				Return(response(stub)) // nolint:bodyclose
+
+			progress := NewMockProgressListener(ctrl)
+			progress.EXPECT().OnStepDone().Times(1)
+			progress.EXPECT().OnStepQueued().Times(1)
+
+			provider, err := New("test", client, logger, progress)
+			require.NoError(t, err)
+
+			alerts, err := provider.Alerts(ctx)
+			assert.NoError(t, err)
+			assert.Empty(t, alerts)
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("bad alerts response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			Return(response("testdata/unavailable.json")) // nolint:bodyclose
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		alerts, err := provider.Alerts(ctx)
+		assert.EqualError(t, err, "grafana: unexpected response status 503: Service unavailable")
+		assert.Nil(t, alerts)
+	})
+
+	t.Run("success fetch on behalf of organization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "2", request.Header.Get("X-Grafana-Org-Id"))
+				return response("testdata/success.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", WithOrg(client, "2"), logger, progress)
+		require.NoError(t, err)
+
+		dashboard, err := provider.Fetch(ctx, "dashboard")
+		assert.NoError(t, err)
+		assert.NotNil(t, dashboard)
+		assert.Equal(t, client, WithOrg(client, ""))
+	})
+
 	t.Run("bad endpoint", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -117,6 +254,333 @@ func TestProvider(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, dashboard)
 	})
+
+	t.Run("success version", func(t *testing.T) {
+		for _, stub := range []string{"testdata/versions.json", "testdata/versions.v10.json"} {
+			ctrl := gomock.NewController(t)
+
+			client := NewMockClient(ctrl)
+			client.EXPECT().
+				Do(gomock.Any()).
+				Return(response(stub)) // nolint:bodyclose
+
+			progress := NewMockProgressListener(ctrl)
+			progress.EXPECT().OnStepDone().Times(1)
+			progress.EXPECT().OnStepQueued().Times(1)
+
+			provider, err := New("test", client, logger, progress)
+			require.NoError(t, err)
+
+			version, err := provider.Version(ctx, "dashboard")
+			assert.NoError(t, err)
+			assert.Equal(t, 3, version)
+
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("success versions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(*http.Request) (*http.Response, error) { return response("testdata/versions.json") }).
+			Times(2)
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(2)
+		progress.EXPECT().OnStepQueued().Times(2)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		created := time.Date(2020, time.November, 1, 12, 0, 0, 0, time.UTC)
+		versions, err := provider.Versions(ctx, "dashboard", created.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []model.DashboardVersion{{Version: 3, Created: created, CreatedBy: "admin"}}, versions)
+
+		versions, err = provider.Versions(ctx, "dashboard", created.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, versions)
+	})
+
+	t.Run("success fetch version", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "test/api/dashboards/uid/dashboard/versions/3", request.URL.Path)
+				return response("testdata/version.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		dashboard, err := provider.FetchVersion(ctx, "dashboard", 3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, dashboard.Version)
+		assert.Equal(t, time.Date(2020, time.November, 1, 12, 0, 0, 0, time.UTC), dashboard.Updated)
+		assert.Equal(t, []model.Query{"apps.services.awesome-service.rpc.server.*.count"}, dashboard.RawData)
+	})
+
+	t.Run("bad version response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			Return(response("testdata/invalid.json")) // nolint:bodyclose
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		version, err := provider.Version(ctx, "dashboard")
+		assert.Error(t, err)
+		assert.Zero(t, version)
+	})
+
+	t.Run("success organizations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "test/api/orgs", request.URL.Path)
+				return response("testdata/orgs.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		orgs, err := provider.Orgs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []model.Org{{ID: 1, Name: "Main Org."}, {ID: 2, Name: "Platform"}}, orgs)
+	})
+
+	t.Run("organizations of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		gomock.InOrder(
+			client.EXPECT().
+				Do(gomock.Any()).
+				Return(response("testdata/forbidden.json")), // nolint:bodyclose
+			client.EXPECT().
+				Do(gomock.Any()).
+				DoAndReturn(func(request *http.Request) (*http.Response, error) {
+					assert.Equal(t, "test/api/user/orgs", request.URL.Path)
+					return response("testdata/user-orgs.json")
+				}),
+		)
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		orgs, err := provider.Orgs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []model.Org{{ID: 2, Name: "Platform"}}, orgs)
+	})
+
+	t.Run("success search", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, "test/api/search", request.URL.Path)
+				assert.Equal(t, "dash-db", request.URL.Query().Get("type"))
+				assert.Equal(t, "DTknF4rik", request.URL.Query().Get("dashboardUIDs"))
+				return response("testdata/search.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		refs, err := provider.Search(ctx, "DTknF4rik")
+		require.NoError(t, err)
+		assert.Equal(t, []model.DashboardRef{{
+			UID:    "DTknF4rik",
+			Title:  "Awesome Service",
+			URL:    "/d/DTknF4rik/awesome-service",
+			Folder: "Services",
+		}}, refs)
+	})
+
+	t.Run("success source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			Return(response("testdata/source.json")) // nolint:bodyclose
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		source, err := provider.Source(ctx, "DTknF4rik")
+		require.NoError(t, err)
+		assert.Equal(t, 42, source.FolderID)
+		assert.Equal(t, 7.0, source.Model["version"])
+		assert.Len(t, source.Model["panels"], 1)
+	})
+
+	t.Run("success save", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				assert.Equal(t, http.MethodPost, request.Method)
+				assert.Equal(t, "test/api/dashboards/db", request.URL.Path)
+
+				var payload struct {
+					Dashboard map[string]interface{} `json:"dashboard"`
+					FolderID  int                    `json:"folderId"`
+					Message   string                 `json:"message"`
+					Overwrite bool                   `json:"overwrite"`
+				}
+				require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
+				assert.Equal(t, 7.0, payload.Dashboard["version"])
+				assert.Equal(t, 42, payload.FolderID)
+				assert.Equal(t, "add panels", payload.Message)
+				assert.False(t, payload.Overwrite)
+				return response("testdata/saved.json")
+			})
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		source := &model.DashboardSource{Model: map[string]interface{}{"version": 7}, FolderID: 42}
+		version, err := provider.Save(ctx, source, "add panels")
+		assert.NoError(t, err)
+		assert.Equal(t, 8, version)
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			Return(response("testdata/mismatch.json")) // nolint:bodyclose
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		source := &model.DashboardSource{Model: map[string]interface{}{"version": 6}}
+		version, err := provider.Save(ctx, source, "add panels")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "changed by someone else")
+		assert.Zero(t, version)
+	})
+
+	t.Run("lost response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := NewMockClient(ctrl)
+		client.EXPECT().
+			Do(gomock.Any()).
+			Return(nil, errors.New("connection reset by peer")).
+			Times(1)
+
+		progress := NewMockProgressListener(ctrl)
+		progress.EXPECT().OnStepDone().Times(1)
+		progress.EXPECT().OnStepQueued().Times(1)
+
+		provider, err := New("test", client, logger, progress)
+		require.NoError(t, err)
+
+		source := &model.DashboardSource{Model: map[string]interface{}{"version": 7}}
+		version, err := provider.Save(ctx, source, "add panels")
+		assert.Error(t, err)
+		assert.Zero(t, version)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	bare, err := ioutil.ReadFile("testdata/model.json")
+	require.NoError(t, err)
+	wrapped, err := json.Marshal(map[string]json.RawMessage{"dashboard": bare})
+	require.NoError(t, err)
+
+	for name, data := range map[string][]byte{"bare": bare, "wrapped": wrapped} {
+		t.Run(name, func(t *testing.T) {
+			dashboard, err := Decode(data)
+			require.NoError(t, err)
+			assert.Equal(t, "DTknF4rik", dashboard.UID)
+			assert.NotEmpty(t, dashboard.RawData)
+			assert.NotEmpty(t, dashboard.Panels)
+		})
+	}
+
+	t.Run("legacy rows", func(t *testing.T) {
+		legacy, err := ioutil.ReadFile("testdata/legacy.json")
+		require.NoError(t, err)
+
+		dashboard, err := Decode(legacy)
+		require.NoError(t, err)
+		assert.Equal(t, []model.Query{
+			"apps.services.awesome-service.rpc.server.get.success.ok.count",
+			"apps.services.awesome-service.rpc.server.put.success.ok.count",
+			"apps.services.awesome-service.go.*.goroutines",
+		}, dashboard.RawData)
+		assert.Len(t, dashboard.Panels, 3)
+		assert.Equal(t, "RPS of put", dashboard.Panels[1].Title)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		dashboard, err := Decode([]byte("invalid"))
+		assert.Error(t, err)
+		assert.Nil(t, dashboard)
+	})
 }
 
 // helpers
@@ -0,0 +1,75 @@
+package grafana
+
+import (
+	"regexp"
+)
+
+const allValue = "$__all"
+
+// expandPanels replaces repeated rows and panels by their copies
+// for each option of the repeat variable, like Grafana does.
+// Panels repeated by a variable without options are left as is.
+func expandPanels(panels []panel, variables []variable) []panel {
+	out := make([]panel, 0, len(panels))
+	for _, panel := range panels {
+		panel.Panels = expandPanels(panel.Panels, variables)
+		if panel.Repeat == "" {
+			out = append(out, panel)
+			continue
+		}
+
+		values := repeatValues(panel.Repeat, variables)
+		if len(values) == 0 {
+			out = append(out, panel)
+			continue
+		}
+		pattern := variablePattern(panel.Repeat)
+		for _, value := range values {
+			out = append(out, substitute(panel, panel.Repeat, pattern, value))
+		}
+	}
+	return out
+}
+
+func repeatValues(name string, variables []variable) []string {
+	for _, variable := range variables {
+		if variable.Name != name {
+			continue
+		}
+		values := make([]string, 0, len(variable.Options))
+		for _, option := range variable.Options {
+			if option.Value != "" && option.Value != allValue {
+				values = append(values, option.Value)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// variablePattern matches all syntaxes of the variable: $name, ${name}, ${name:format} and [[name]].
+func variablePattern(name string) *regexp.Regexp {
+	name = regexp.QuoteMeta(name)
+	return regexp.MustCompile(`\$\{` + name + `(?::[^}]*)?\}|\[\[` + name + `(?::[^\]]*)?\]\]|\$` + name + `\b`)
+}
+
+func substitute(in panel, name string, pattern *regexp.Regexp, value string) panel {
+	out := in
+	out.Repeat = ""
+	out.repeated = make(map[string]string, len(in.repeated)+1)
+	for variable, value := range in.repeated {
+		out.repeated[variable] = value
+	}
+	out.repeated[name] = value
+	out.Title = pattern.ReplaceAllLiteralString(in.Title, value)
+	out.Targets = make([]target, 0, len(in.Targets))
+	for _, target := range in.Targets {
+		target.Query = pattern.ReplaceAllLiteralString(target.Query, value)
+		out.Targets = append(out.Targets, target)
+	}
+	out.Panels = make([]panel, 0, len(in.Panels))
+	for _, nested := range in.Panels {
+		out.Panels = append(out.Panels, substitute(nested, name, pattern, value))
+	}
+	return out
+}
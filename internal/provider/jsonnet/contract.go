@@ -0,0 +1,10 @@
+package jsonnet
+
+import "context"
+
+//go:generate mockgen -source $GOFILE -destination mocks_test.go -package ${GOPACKAGE}_test
+
+// A Runner runs the jsonnet command with arguments and returns its output.
+type Runner interface {
+	Output(context.Context, []string) ([]byte, error)
+}
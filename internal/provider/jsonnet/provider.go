@@ -0,0 +1,107 @@
+package jsonnet
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kamilsk/grafaman/internal/model"
+	"github.com/kamilsk/grafaman/internal/provider/grafana"
+)
+
+// Options contains arguments to evaluate a Jsonnet file.
+type Options struct {
+	LibPaths []string
+	ExtStr   map[string]string
+	ExtCode  map[string]string
+	TLAStr   map[string]string
+	TLACode  map[string]string
+}
+
+// Args returns command line arguments of the jsonnet command to evaluate the file.
+func (options Options) Args(filename string) []string {
+	args := make([]string, 0, 2*len(options.LibPaths)+1)
+	for _, path := range options.LibPaths {
+		args = append(args, "--jpath", path)
+	}
+	for _, group := range []struct {
+		flag   string
+		values map[string]string
+	}{
+		{"--ext-str", options.ExtStr},
+		{"--ext-code", options.ExtCode},
+		{"--tla-str", options.TLAStr},
+		{"--tla-code", options.TLACode},
+	} {
+		keys := make([]string, 0, len(group.values))
+		for key := range group.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			args = append(args, group.flag, key+"="+group.values[key])
+		}
+	}
+	return append(args, filename)
+}
+
+// Binary returns a Runner based on the jsonnet executable,
+// e.g. https://github.com/google/go-jsonnet.
+func Binary(path string) Runner {
+	return binary(path)
+}
+
+type binary string
+
+// Output runs the executable and returns its stdout.
+func (path binary) Output(ctx context.Context, args []string) ([]byte, error) {
+	stderr := bytes.NewBuffer(nil)
+	command := exec.CommandContext(ctx, string(path), args...)
+	command.Stderr = stderr
+	output, err := command.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.Wrap(err, message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// New returns an instance of Jsonnet dashboard provider.
+func New(runner Runner, options Options, logger *logrus.Logger) *provider {
+	return &provider{runner: runner, options: options, logger: logger}
+}
+
+type provider struct {
+	runner  Runner
+	options Options
+	logger  *logrus.Logger
+}
+
+// Fetch evaluates a Jsonnet file and extracts queries and variables
+// from the resulting dashboard JSON model in the same way as the Grafana provider.
+func (provider *provider) Fetch(ctx context.Context, filename string) (*model.Dashboard, error) {
+	logger := provider.logger.WithField("file", filename)
+	logger.Info("start to evaluate jsonnet")
+	output, err := provider.runner.Output(ctx, provider.options.Args(filename))
+	if err != nil {
+		logger.WithError(err).Error("fail evaluate jsonnet")
+		return nil, errors.Wrapf(err, "jsonnet: evaluate %q", filename)
+	}
+	logger.Info("success evaluate jsonnet")
+
+	dashboard, err := grafana.Decode(output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "jsonnet: decode result of %q", filename)
+	}
+	if dashboard.UID == "" {
+		dashboard.UID = filename
+	}
+	return dashboard, nil
+}
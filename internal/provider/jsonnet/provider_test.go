@@ -0,0 +1,103 @@
+package jsonnet_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/kamilsk/grafaman/internal/provider/jsonnet"
+)
+
+func TestOptions_Args(t *testing.T) {
+	options := Options{
+		LibPaths: []string{"vendor", "lib"},
+		ExtStr:   map[string]string{"env": "prod", "app": "awesome-service"},
+		TLACode:  map[string]string{"replicas": "3"},
+	}
+	assert.Equal(t, []string{
+		"--jpath", "vendor",
+		"--jpath", "lib",
+		"--ext-str", "app=awesome-service",
+		"--ext-str", "env=prod",
+		"--tla-code", "replicas=3",
+		"dashboard.jsonnet",
+	}, options.Args("dashboard.jsonnet"))
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	t.Run("success fetch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		data, err := ioutil.ReadFile("testdata/dashboard.json")
+		require.NoError(t, err)
+
+		runner := NewMockRunner(ctrl)
+		runner.EXPECT().
+			Output(ctx, []string{"--jpath", "vendor", "dashboard.jsonnet"}).
+			Return(data, nil)
+
+		provider := New(runner, Options{LibPaths: []string{"vendor"}}, logger)
+		dashboard, err := provider.Fetch(ctx, "dashboard.jsonnet")
+		require.NoError(t, err)
+		assert.Equal(t, "DTknF4rik", dashboard.UID)
+		assert.NotEmpty(t, dashboard.RawData)
+	})
+
+	t.Run("evaluation error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		runner := NewMockRunner(ctrl)
+		runner.EXPECT().
+			Output(ctx, gomock.Any()).
+			Return(nil, errors.New("RUNTIME ERROR: field does not exist: panels"))
+
+		provider := New(runner, Options{}, logger)
+		dashboard, err := provider.Fetch(ctx, "dashboard.jsonnet")
+		assert.Error(t, err)
+		assert.Nil(t, dashboard)
+	})
+
+	t.Run("bad result", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		runner := NewMockRunner(ctrl)
+		runner.EXPECT().
+			Output(ctx, gomock.Any()).
+			Return([]byte("[]"), nil)
+
+		provider := New(runner, Options{}, logger)
+		dashboard, err := provider.Fetch(ctx, "dashboard.jsonnet")
+		assert.Error(t, err)
+		assert.Nil(t, dashboard)
+	})
+}
+
+func TestBinary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("any JSON is valid Jsonnet", func(t *testing.T) {
+		output, err := Binary("cat").Output(ctx, []string{"testdata/dashboard.json"})
+		assert.NoError(t, err)
+		assert.Contains(t, string(output), "DTknF4rik")
+	})
+
+	t.Run("unknown binary", func(t *testing.T) {
+		output, err := Binary("grafaman-unknown-jsonnet").Output(ctx, []string{"dashboard.jsonnet"})
+		assert.Error(t, err)
+		assert.Nil(t, output)
+	})
+}
@@ -18,3 +18,13 @@ type CoverageReportPrinter interface {
 type MetricPrinter interface {
 	PrintMetrics(model.Metrics) error
 }
+
+// An Explainer defines behavior of a metric coverage explainer.
+type Explainer interface {
+	Explain(model.Metric) (model.Explanation, error)
+}
+
+// An ExplanationPrinter defines behavior of an explanation printer.
+type ExplanationPrinter interface {
+	PrintExplanation(model.Explanation) error
+}
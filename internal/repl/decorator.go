@@ -11,3 +11,16 @@ func Prefix(prefix string, fn func(string)) func(string) {
 		fn(prefix + "." + strings.TrimSpace(input))
 	}
 }
+
+// Command routes an input started with the command name to the specific handler
+// without the name, otherwise, it passes the input to the fallback.
+func Command(name string, fn, fallback func(string)) func(string) {
+	name += " "
+	return func(input string) {
+		if trimmed := strings.TrimSpace(input); strings.HasPrefix(trimmed, name) {
+			fn(strings.TrimSpace(strings.TrimPrefix(trimmed, name)))
+			return
+		}
+		fallback(input)
+	}
+}
@@ -44,3 +44,20 @@ func TestPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestCommand(t *testing.T) {
+	var handled, fallen []string
+	executor := Command(
+		"explain",
+		func(input string) { handled = append(handled, input) },
+		func(input string) { fallen = append(fallen, input) },
+	)
+
+	executor("explain metric.a")
+	executor("  explain   metric.b ")
+	executor("explainer.metric")
+	executor("metric.*")
+
+	assert.Equal(t, []string{"metric.a", "metric.b"}, handled)
+	assert.Equal(t, []string{"explainer.metric", "metric.*"}, fallen)
+}
@@ -34,3 +34,21 @@ func NewMetricExecutor(
 		}
 	}
 }
+
+func NewExplainExecutor(
+	explainer Explainer,
+	printer ExplanationPrinter,
+	logger *logrus.Logger,
+) func(string) {
+	return func(metric string) {
+		explanation, err := explainer.Explain(model.Metric(metric))
+		if err != nil {
+			logger.WithError(err).Error("repl: explain metric")
+			return
+		}
+		if err := printer.PrintExplanation(explanation); err != nil {
+			logger.WithError(err).Error("repl: print explanation")
+			return
+		}
+	}
+}
@@ -100,3 +100,43 @@ func TestMetricExecutor(t *testing.T) {
 		assert.NotPanics(t, func() { executor("metric.*") })
 	})
 }
+
+func TestExplainExecutor(t *testing.T) {
+	explanation := model.Explanation{Metric: "metric.a.ok"}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		explainer := NewMockExplainer(ctrl)
+		explainer.EXPECT().
+			Explain(explanation.Metric).
+			Return(explanation, nil)
+
+		printer := NewMockExplanationPrinter(ctrl)
+		printer.EXPECT().
+			PrintExplanation(explanation).
+			Return(nil)
+
+		executor := NewExplainExecutor(explainer, printer, logger)
+		assert.NotPanics(t, func() { executor("metric.a.ok") })
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		explainer := NewMockExplainer(ctrl)
+		explainer.EXPECT().
+			Explain(explanation.Metric).
+			Return(explanation, errors.New("invalid query"))
+
+		printer := NewMockExplanationPrinter(ctrl)
+
+		executor := NewExplainExecutor(explainer, printer, logger)
+		assert.NotPanics(t, func() { executor("metric.a.ok") })
+	})
+}
@@ -122,3 +122,78 @@ func (mr *MockMetricPrinterMockRecorder) PrintMetrics(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintMetrics", reflect.TypeOf((*MockMetricPrinter)(nil).PrintMetrics), arg0)
 }
+
+// MockExplainer is a mock of Explainer interface
+type MockExplainer struct {
+	ctrl     *gomock.Controller
+	recorder *MockExplainerMockRecorder
+}
+
+// MockExplainerMockRecorder is the mock recorder for MockExplainer
+type MockExplainerMockRecorder struct {
+	mock *MockExplainer
+}
+
+// NewMockExplainer creates a new mock instance
+func NewMockExplainer(ctrl *gomock.Controller) *MockExplainer {
+	mock := &MockExplainer{ctrl: ctrl}
+	mock.recorder = &MockExplainerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockExplainer) EXPECT() *MockExplainerMockRecorder {
+	return m.recorder
+}
+
+// Explain mocks base method
+func (m *MockExplainer) Explain(arg0 model.Metric) (model.Explanation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Explain", arg0)
+	ret0, _ := ret[0].(model.Explanation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Explain indicates an expected call of Explain
+func (mr *MockExplainerMockRecorder) Explain(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Explain", reflect.TypeOf((*MockExplainer)(nil).Explain), arg0)
+}
+
+// MockExplanationPrinter is a mock of ExplanationPrinter interface
+type MockExplanationPrinter struct {
+	ctrl     *gomock.Controller
+	recorder *MockExplanationPrinterMockRecorder
+}
+
+// MockExplanationPrinterMockRecorder is the mock recorder for MockExplanationPrinter
+type MockExplanationPrinterMockRecorder struct {
+	mock *MockExplanationPrinter
+}
+
+// NewMockExplanationPrinter creates a new mock instance
+func NewMockExplanationPrinter(ctrl *gomock.Controller) *MockExplanationPrinter {
+	mock := &MockExplanationPrinter{ctrl: ctrl}
+	mock.recorder = &MockExplanationPrinterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockExplanationPrinter) EXPECT() *MockExplanationPrinterMockRecorder {
+	return m.recorder
+}
+
+// PrintExplanation mocks base method
+func (m *MockExplanationPrinter) PrintExplanation(arg0 model.Explanation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrintExplanation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrintExplanation indicates an expected call of PrintExplanation
+func (mr *MockExplanationPrinterMockRecorder) PrintExplanation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintExplanation", reflect.TypeOf((*MockExplanationPrinter)(nil).PrintExplanation), arg0)
+}